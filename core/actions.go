@@ -0,0 +1,345 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/vcs"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+var (
+	ErrActionNotFound   = errors.New("action not found for the project")
+	ErrMalformedCommand = errors.New("action command has an unclosed quote or a trailing backslash")
+	ErrBlankCommand     = errors.New("action command is blank")
+)
+
+// actionScope ranks how specific the projects an action applies to are.
+type actionScope int
+
+const (
+	globalScope actionScope = iota
+	tagScope
+	projectScope
+)
+
+func NewActions(projects *Projects) *Actions {
+	return &Actions{projects: projects}
+}
+
+// Actions is the frontend service for custom actions, user defined commands run against a project.
+type Actions struct {
+	runtime  *platform.Runtime
+	log      platform.Logger
+	bus      *events.Bus
+	db       *database.DB
+	projects *Projects
+}
+
+func (a *Actions) start(runtime *platform.Runtime) error {
+	a.runtime = runtime
+	a.log = a.runtime.Log("actions")
+	a.bus = events.NewBus(a.runtime.Events, a.log)
+	a.db = database.Service()
+
+	a.registerEvents()
+
+	return nil
+}
+
+// Registers events which can be called via the wails runtime
+func (a *Actions) registerEvents() {
+	a.bus.Handle(events.ActionRun, func(payload interface{}) error {
+		run := payload.(dto.ActionRun)
+
+		return a.Run(run.Path, run.Action)
+	})
+}
+
+// GetActions returns every custom action.
+func (a *Actions) GetActions() (_ []dto.Action, err error) {
+	defer failure.Wrap(&err)
+
+	actions, err := a.db.GetActions()
+	if errors.Is(err, database.ErrNoRecords) {
+		return []dto.Action{}, nil
+	}
+
+	return actions, err
+}
+
+// UpsertAction creates or replaces a custom action decoded from data.
+func (a *Actions) UpsertAction(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	var action dto.Action
+
+	err = mapstructure.Decode(data, &action)
+	if err != nil {
+		return fmt.Errorf("unable to decode action: %w", err)
+	}
+
+	err = action.Validate()
+	if err == nil {
+		_, err = splitCommand(action.Command)
+	}
+
+	if err != nil {
+		return failure.New(failure.Invalid, err)
+	}
+
+	return a.db.UpsertAction(action)
+}
+
+// RemoveAction removes the custom action with the name and scope decoded from data.
+func (a *Actions) RemoveAction(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	var action dto.Action
+
+	err = mapstructure.Decode(data, &action)
+	if err != nil {
+		return fmt.Errorf("unable to decode action: %w", err)
+	}
+
+	return a.db.DeleteAction(action)
+}
+
+// ProjectActions returns the actions which apply to the project at path: its own actions, then those of its tags, then
+// the global ones, each by name. An action overrides the less specific actions of the same name.
+func (a *Actions) ProjectActions(path string) (_ []dto.Action, err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := a.projects.find(path)
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	actions, err := a.GetActions()
+	if err != nil {
+		return nil, err
+	}
+
+	return projectActions(project, actions), nil
+}
+
+// Run runs the action called name against the project at path. Commands are started without waiting for them to
+// finish.
+func (a *Actions) Run(path, name string) (err error) {
+	defer failure.Wrap(&err)
+
+	actions, err := a.ProjectActions(path)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		if action.Name == name {
+			return a.run(path, action)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrActionNotFound, name)
+}
+
+func (a *Actions) run(path string, action dto.Action) error {
+	project, _ := a.projects.find(path)
+	abs := a.projects.absPath(path)
+
+	// a sub-project shares the repository of its parent
+	repo := project
+	if parent, ok := a.projects.find(project.Parent); ok && project.Parent != "" {
+		repo = parent
+	}
+
+	variables := actionVariables(project, abs, repo, a.projects.absPath(repo.Path))
+
+	args, err := expandCommand(action.Command, variables)
+	if err != nil {
+		return err
+	}
+
+	a.log.DebugFields("Running action", platform.Fields{"path": path, "action": action.Name, "args": args})
+
+	if len(args) == 1 && (strings.HasPrefix(args[0], "http://") || strings.HasPrefix(args[0], "https://")) {
+		return a.runtime.Browser.OpenURL(args[0])
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = abs
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	// reap the process once it exits so it doesn't linger as a zombie
+	go func() { _ = cmd.Wait() }()
+
+	return nil
+}
+
+// projectActions filters actions down to those applying to project, most specific first. Of several actions with the
+// same name only the most specific is kept, the first by tag when two tags define it.
+func projectActions(project dto.Project, actions []dto.Action) []dto.Action {
+	scope := func(action dto.Action) actionScope {
+		switch {
+		case action.Project != "":
+			return projectScope
+		case action.Tag != "":
+			return tagScope
+		default:
+			return globalScope
+		}
+	}
+
+	applied := make([]dto.Action, 0, len(actions))
+
+	for _, action := range actions {
+		if action.Project != "" && action.Project != project.Path {
+			continue
+		}
+
+		if action.Tag != "" && !hasTags(project, []string{action.Tag}) {
+			continue
+		}
+
+		applied = append(applied, action)
+	}
+
+	sort.SliceStable(applied, func(i, j int) bool {
+		if scope(applied[i]) != scope(applied[j]) {
+			return scope(applied[i]) > scope(applied[j])
+		}
+
+		if applied[i].Name != applied[j].Name {
+			return applied[i].Name < applied[j].Name
+		}
+
+		return applied[i].Tag < applied[j].Tag
+	})
+
+	seen := make(map[string]bool, len(applied))
+	unique := applied[:0]
+
+	for _, action := range applied {
+		if seen[action.Name] {
+			continue
+		}
+
+		seen[action.Name] = true
+		unique = append(unique, action)
+	}
+
+	return unique
+}
+
+// actionVariables returns the values of the variables of an action command for the project at abs. The remote is the
+// first git remote and the branch the checked out one of repo at repoAbs, both blank when unknown.
+func actionVariables(project dto.Project, abs string, repo dto.Project, repoAbs string) map[string]string {
+	variables := map[string]string{
+		"{path}":   abs,
+		"{name}":   filepath.Base(project.Path),
+		"{remote}": "",
+		"{branch}": "",
+	}
+
+	if len(repo.Remotes) > 0 {
+		variables["{remote}"] = repo.Remotes[0]
+	}
+
+	git, err := vcs.Open(repoAbs)
+	if err == nil && git != nil {
+		variables["{branch}"] = vcs.CurrentBranch(git)
+	}
+
+	return variables
+}
+
+// expandCommand splits command into arguments and replaces the variables in each of them.
+func expandCommand(command string, variables map[string]string) ([]string, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []string
+	for name, value := range variables {
+		pairs = append(pairs, name, value)
+	}
+
+	replacer := strings.NewReplacer(pairs...)
+
+	for i := range args {
+		args[i] = replacer.Replace(args[i])
+	}
+
+	return args, nil
+}
+
+// splitCommand splits a command line into arguments on whitespace. Single quotes keep everything up to the closing
+// quote, double quotes keep whitespace and a backslash escapes the next character outside of single quotes.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		escaped bool
+		started bool
+	)
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			started = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			started = true
+		case unicode.IsSpace(r):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+
+			started = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, ErrMalformedCommand
+	}
+
+	if started {
+		args = append(args, current.String())
+	}
+
+	if len(args) == 0 {
+		return nil, ErrBlankCommand
+	}
+
+	return args, nil
+}
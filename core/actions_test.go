@@ -0,0 +1,114 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+		err     error
+	}{
+		{command: "lazygit", want: []string{"lazygit"}},
+		{command: "  kitty  -d {path}\tlazygit ", want: []string{"kitty", "-d", "{path}", "lazygit"}},
+		{command: `code "my project"`, want: []string{"code", "my project"}},
+		{command: `sh -c 'echo "$HOME" \n'`, want: []string{"sh", "-c", `echo "$HOME" \n`}},
+		{command: `echo "a \"b\""`, want: []string{"echo", `a "b"`}},
+		{command: `echo a\ b`, want: []string{"echo", "a b"}},
+		{command: `echo "" ''`, want: []string{"echo", "", ""}},
+		{command: `echo x"y z"`, want: []string{"echo", "xy z"}},
+		{command: `echo "open`, err: ErrMalformedCommand},
+		{command: `echo 'open`, err: ErrMalformedCommand},
+		{command: `echo \`, err: ErrMalformedCommand},
+		{command: " \t ", err: ErrBlankCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			args, err := splitCommand(tt.command)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("split into %q, want %q", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCommand(t *testing.T) {
+	variables := map[string]string{
+		"{path}":   "/src/my app",
+		"{name}":   "my app",
+		"{remote}": "git@github.com:me/app.git",
+		"{branch}": "",
+	}
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{command: "code {path}", want: []string{"code", "/src/my app"}},
+		{command: "tmux new -s {name} -c {path}", want: []string{"tmux", "new", "-s", "my app", "-c", "/src/my app"}},
+		{command: "echo {remote}#{branch}", want: []string{"echo", "git@github.com:me/app.git#"}},
+		{command: "echo '{name}' {unknown}", want: []string{"echo", "my app", "{unknown}"}},
+		{command: "echo {name}{name}", want: []string{"echo", "my appmy app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			args, err := expandCommand(tt.command, variables)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("expanded into %q, want %q", args, tt.want)
+			}
+		})
+	}
+
+	// values are substituted after splitting, so they can't inject arguments or quotes
+	args, err := expandCommand("echo {name}", map[string]string{"{name}": `a "b" c`})
+	if err != nil || !reflect.DeepEqual(args, []string{"echo", `a "b" c`}) {
+		t.Errorf("expanded into %q, %v, want the value as one argument", args, err)
+	}
+}
+
+func TestProjectActions(t *testing.T) {
+	project := dto.Project{Path: "app", Tags: []string{"client"}, AutoTags: []string{"go"}}
+
+	actions := []dto.Action{
+		{Name: "shell", Command: "global shell"},
+		{Name: "open", Command: "global open"},
+		{Name: "open", Command: "go open", Tag: "go"},
+		{Name: "open", Command: "client open", Tag: "client"},
+		{Name: "shell", Command: "app shell", Project: "app"},
+		{Name: "deploy", Command: "client deploy", Tag: "Client"},
+		{Name: "test", Command: "other test", Project: "other"},
+		{Name: "lint", Command: "node lint", Tag: "node"},
+		{Name: "docs", Command: "global docs"},
+	}
+
+	want := []dto.Action{
+		{Name: "shell", Command: "app shell", Project: "app"},
+		{Name: "deploy", Command: "client deploy", Tag: "Client"},
+		{Name: "open", Command: "client open", Tag: "client"},
+		{Name: "docs", Command: "global docs"},
+	}
+
+	got := projectActions(project, actions)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("actions\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := projectActions(dto.Project{Path: "plain"}, nil); len(got) != 0 {
+		t.Errorf("actions %+v without any defined, want none", got)
+	}
+}
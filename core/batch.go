@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/vcs"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// DefaultBatchConcurrency is the number of repositories operated on at once when a batch request doesn't specify one.
+const DefaultBatchConcurrency = 4
+
+// Batch decodes a dto.BatchRequest from data and runs it, see runBatch.
+func (p *Projects) Batch(data map[string]interface{}) (_ []dto.BatchResult, err error) {
+	defer failure.Wrap(&err)
+
+	var req dto.BatchRequest
+
+	err = mapstructure.Decode(data, &req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode batch request: %w", err)
+	}
+
+	return p.runBatch(req)
+}
+
+// batch is a batch request which was checked and is ready to run.
+type batch struct {
+	op          vcs.Operation
+	opts        vcs.Options
+	projects    []dto.Project
+	concurrency int
+}
+
+// runBatch runs a git operation across every project matched by the request selector, see planBatch and execute.
+func (p *Projects) runBatch(req dto.BatchRequest) ([]dto.BatchResult, error) {
+	b, err := p.planBatch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.execute(b), nil
+}
+
+// planBatch checks the request and selects the projects it applies to, without running anything yet.
+func (p *Projects) planBatch(req dto.BatchRequest) (batch, error) {
+	op, err := vcs.ParseOperation(req.Operation)
+	if err != nil {
+		return batch{}, err
+	}
+
+	if op == vcs.OperationCheckout && req.Branch == "" {
+		return batch{}, vcs.ErrBranchRequired
+	}
+
+	projects, err := p.Select(req.Selector)
+	if err != nil {
+		return batch{}, err
+	}
+
+	concurrency := req.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	return batch{op: op, opts: vcs.Options{Branch: req.Branch}, projects: projects, concurrency: concurrency}, nil
+}
+
+// execute runs the batch, at most b.concurrency projects at a time. Each finished project is emitted as a
+// projects.batch.progress event. Results are returned in selection order and failures are recorded per project rather
+// than aborting the batch.
+func (p *Projects) execute(b batch) []dto.BatchResult {
+	p.log.DebugFields("Running batch operation",
+		platform.Fields{"operation": b.op, "projects": len(b.projects), "concurrency": b.concurrency})
+
+	var (
+		results = make([]dto.BatchResult, len(b.projects))
+		sem     = make(chan struct{}, b.concurrency)
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		done    int
+	)
+
+	for i := range b.projects {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = p.runBatchOperation(b.projects[i], b.op, b.opts)
+
+			mu.Lock()
+			done++
+			progress := dto.BatchProgress{Result: results[i], Done: done, Total: len(b.projects)}
+			mu.Unlock()
+
+			p.bus.Emit(events.ProjectsBatchProgress, progress)
+		}(i)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func (p *Projects) runBatchOperation(project dto.Project, op vcs.Operation, opts vcs.Options) dto.BatchResult {
+	start := time.Now()
+	result := dto.BatchResult{Path: project.Path, Operation: string(op)}
+
+	msg, err := vcs.Run(p.absPath(project.Path), op, opts)
+	if err != nil {
+		p.log.DebugFields("Batch operation failed", platform.Fields{"path": project.Path, "operation": op, "error": err})
+
+		result.Error = err.Error()
+	}
+
+	result.Message = msg
+	result.Duration = time.Since(start).Milliseconds()
+
+	return result
+}
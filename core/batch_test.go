@@ -0,0 +1,66 @@
+package core
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+)
+
+func TestBatchEvent(t *testing.T) {
+	dir := newProjectDir(t)
+	commitRepo(t, filepath.Join(dir, "app"))
+
+	app := startApp(t, dir, "")
+
+	tests := []struct {
+		name string
+		req  dto.BatchRequest
+	}{
+		{name: "unknown operation", req: dto.BatchRequest{Operation: "rebase"}},
+		{name: "checkout without branch", req: dto.BatchRequest{Operation: "checkout"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := strconv.Itoa(i)
+
+			app.platform.Events.Emit(events.ProjectsBatch, tt.req, id)
+
+			result := lastResult(t, app, events.ProjectsBatch)
+			if result.ID != id || result.Error == nil || result.Error.Code != failure.Invalid {
+				t.Errorf("answered with %+v, want an invalid error for id %s", result, id)
+			}
+		})
+	}
+
+	if n := len(app.platform.Events.Emitted(events.ProjectsBatchCompleted)); n != 0 {
+		t.Fatalf("%d batches completed, want the rejected ones never started", n)
+	}
+
+	app.platform.Events.Emit(events.ProjectsBatch, dto.BatchRequest{Operation: "status"}, "ok")
+
+	result := lastResult(t, app, events.ProjectsBatch)
+	if result.ID != "ok" || result.Error != nil {
+		t.Fatalf("answered with %+v, want the batch accepted", result)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for len(app.platform.Events.Emitted(events.ProjectsBatchCompleted)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("the batch didn't complete")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	results := app.platform.Events.Emitted(events.ProjectsBatchCompleted)[0][0].([]dto.BatchResult)
+	if len(results) != 1 || results[0].Path != "app" || results[0].Error != "" {
+		t.Errorf("completed with %+v, want the status of app", results)
+	}
+}
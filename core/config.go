@@ -0,0 +1,158 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+)
+
+func NewConfig() *Config {
+	return new(Config)
+}
+
+type Config struct {
+	config  *config.Config
+	runtime *platform.Runtime
+	log     platform.Logger
+	bus     *events.Bus
+	// unwatch stops watching the config file for edits
+	unwatch func() error
+}
+
+func (c *Config) start(runtime *platform.Runtime) error {
+	c.runtime = runtime
+	c.config = config.Service()
+	c.log = c.runtime.Log("config")
+	c.bus = events.NewBus(c.runtime.Events, c.log)
+
+	c.registerEvents()
+	c.watch()
+
+	return nil
+}
+
+func (c *Config) stop() {
+	c.stopWatching()
+}
+
+// watch starts watching the config file for edits, replacing any previous watch. Hot reloading is a convenience,
+// proman works without it, so failures are only logged.
+func (c *Config) watch() {
+	c.stopWatching()
+
+	unwatch, err := c.config.Watch(c.reloaded, c.rejected)
+	if err != nil {
+		c.log.ErrorFields("Unable to watch config file for edits", platform.Fields{"error": err})
+	}
+
+	c.unwatch = unwatch
+}
+
+func (c *Config) stopWatching() {
+	if c.unwatch == nil {
+		return
+	}
+
+	err := c.unwatch()
+	if err != nil {
+		c.log.ErrorFields("Unable to stop watching config file", platform.Fields{"error": err})
+	}
+
+	c.unwatch = nil
+}
+
+// reloaded is called after the config file was edited by hand. Services listening on config.changed apply the new
+// config themselves.
+func (c *Config) reloaded(previous, current dto.ConfigSchema) {
+	c.log.Info("Config file changed, reloading")
+
+	c.bus.Emit(events.ConfigChanged, current, previous)
+}
+
+// rejected is called when a hand edit of the config file is invalid and was not applied. The frontend is sent the
+// error and the invalid keys, if it was a validation error.
+func (c *Config) rejected(err error) {
+	c.log.ErrorFields("Ignoring invalid config file edit", platform.Fields{"error": err})
+
+	fields := []dto.FieldError{}
+
+	var invalid config.ValidationError
+	if errors.As(err, &invalid) {
+		fields = invalid
+	}
+
+	c.bus.Emit(events.ConfigInvalid, err.Error(), fields)
+}
+
+// Registers events which can be called via the wails runtime
+func (c *Config) registerEvents() {
+	// Updates config based on an event
+	c.bus.Handle(events.ConfigUpdate, func(payload interface{}) error {
+		data := payload.(map[string]interface{})
+
+		c.log.Debugf("Updating config", data)
+
+		return c.Update(data)
+	})
+
+	c.bus.Handle(events.ConfigSelectProjectDirectory, func(interface{}) error {
+		dir := c.runtime.Dialog.SelectDirectory()
+
+		// blank return means that the user hit cancel
+		if dir == "" {
+			return nil
+		}
+
+		msg := ""
+
+		err := c.Update(map[string]interface{}{
+			"project_directory": dir,
+		})
+		if err != nil {
+			msg = failure.Describe(err).Message
+		}
+
+		c.bus.Emit(events.ConfigSetProjectDirectory, dir, msg)
+
+		return err
+	})
+}
+
+// Get unmarshals config using mapstructure
+func (c *Config) Get() (_ dto.ConfigSchema, err error) {
+	defer failure.Wrap(&err)
+
+	return c.config.Unmarshal()
+}
+
+// Update reads values from a map[string]interface and writes them to the config file. Nothing is written when any
+// value is invalid, the returned config.ValidationError lists every invalid key.
+func (c *Config) Update(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	errs := config.Validate(data)
+	if len(errs) > 0 {
+		c.log.DebugFields("Rejected invalid config from frontend", platform.Fields{"errors": errs})
+
+		return config.ValidationError(errs)
+	}
+
+	err = c.config.MergeConfigMap(data)
+	if err != nil {
+		c.log.Errorf("Error merging config from frontend", err)
+
+		return err
+	}
+
+	c.log.Debugf("Updating config", data)
+
+	return c.save()
+}
+
+func (c *Config) save() error {
+	return c.config.WriteConfig()
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattouille/proman/dirs"
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+)
+
+// lastResult returns the last result notification of the request event name.
+func lastResult(t *testing.T, app *testApp, name string) dto.EventResult {
+	t.Helper()
+
+	emitted := app.platform.Events.Emitted(events.ResultName(name))
+	if len(emitted) == 0 {
+		t.Fatalf("%s was not answered", name)
+	}
+
+	return emitted[len(emitted)-1][0].(dto.EventResult)
+}
+
+func TestConfigUpdate(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "disk_scan_interval_hours = 24\n")
+
+	err := app.Config.Update(map[string]interface{}{"disk_scan_interval_hours": float64(12)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cfg, err := app.Config.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.DiskScanIntervalHours != 12 {
+		t.Errorf("disk scan interval %d, want 12", cfg.DiskScanIntervalHours)
+	}
+
+	file := filepath.Join(os.Getenv(dirs.HomeEnv), "config.toml")
+
+	written, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unable to read config: %s", err)
+	}
+
+	if !strings.Contains(string(written), "disk_scan_interval_hours = 12") {
+		t.Errorf("the interval was not written:\n%s", written)
+	}
+
+	err = app.Config.Update(map[string]interface{}{
+		"disk_scan_interval_hours": 0,
+		"forge_cache_ttl_minutes":  5,
+		"unknown":                  true,
+	})
+
+	var e *failure.Error
+	if !errors.As(err, &e) || e.Description.Code != failure.Invalid {
+		t.Fatalf("error %v, want an invalid error", err)
+	}
+
+	if len(e.Description.Fields) != 2 {
+		t.Errorf("fields %v, want the interval and the unknown key", e.Description.Fields)
+	}
+
+	unchanged, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unable to read config: %s", err)
+	}
+
+	if string(unchanged) != string(written) {
+		t.Errorf("config was written despite invalid values:\n%s", unchanged)
+	}
+}
+
+func TestConfigUpdateEvent(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "")
+
+	app.platform.Events.Emit(events.ConfigUpdate, map[string]interface{}{"health_stale_days": 30}, "1")
+
+	result := lastResult(t, app, events.ConfigUpdate)
+	if result.ID != "1" || result.Error != nil {
+		t.Errorf("answered with %+v, want success for id 1", result)
+	}
+
+	app.platform.Events.Emit(events.ConfigUpdate, map[string]interface{}{"health_stale_days": -1}, "2")
+
+	result = lastResult(t, app, events.ConfigUpdate)
+	if result.ID != "2" || result.Error == nil || result.Error.Code != failure.Invalid {
+		t.Fatalf("answered with %+v, want an invalid error for id 2", result)
+	}
+
+	if len(result.Error.Fields) != 1 || result.Error.Fields[0].Field != "health_stale_days" {
+		t.Errorf("fields %v, want health_stale_days", result.Error.Fields)
+	}
+}
+
+func TestConfigForgeToken(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "[[forges]]\nhost = \"git.example.com\"\nkind = \"gitlab\"\ntoken = \"secret\"\n")
+
+	cfg, err := app.Config.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(cfg.Forges) != 1 || cfg.Forges[0].Token != "" {
+		t.Errorf("forges %+v, want the token in the config file ignored", cfg.Forges)
+	}
+
+	err = app.Config.Update(map[string]interface{}{
+		"forges": []interface{}{
+			map[string]interface{}{"host": "git.example.com", "kind": "gitlab", "token": "secret"},
+		},
+	})
+
+	var e *failure.Error
+	if !errors.As(err, &e) || len(e.Description.Fields) != 1 || e.Description.Fields[0].Field != "forges[0].token" {
+		t.Errorf("error %v, want the token rejected", err)
+	}
+}
+
+func TestSelectProjectDirectory(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "")
+
+	// cancelling the dialog changes nothing
+	app.platform.Events.Emit(events.ConfigSelectProjectDirectory, "1")
+
+	if n := len(app.platform.Events.Emitted(events.ConfigSetProjectDirectory)); n != 0 {
+		t.Errorf("%d %s events after cancelling", n, events.ConfigSetProjectDirectory)
+	}
+
+	work := filepath.Join(filepath.Dir(dir), "work")
+	mkdirs(t, filepath.Dir(dir), "work")
+
+	app.platform.Dialog.Directory = work
+
+	app.platform.Events.Emit(events.ConfigSelectProjectDirectory, "2")
+
+	set := app.platform.Events.Emitted(events.ConfigSetProjectDirectory)
+	if len(set) != 1 || set[0][0] != work || set[0][1] != "" {
+		t.Fatalf("notified %v, want %s without error", set, work)
+	}
+
+	result := lastResult(t, app, events.ConfigSelectProjectDirectory)
+	if result.ID != "2" || result.Error != nil {
+		t.Errorf("answered with %+v, want success for id 2", result)
+	}
+
+	cfg, err := app.Config.Get()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.ProjectDirectory != work {
+		t.Errorf("project directory %s, want %s", cfg.ProjectDirectory, work)
+	}
+
+	// a directory which can't be used fails the request as well as the notification
+	app.platform.Dialog.Directory = filepath.Join(work, "missing")
+
+	app.platform.Events.Emit(events.ConfigSelectProjectDirectory, "3")
+
+	set = app.platform.Events.Emitted(events.ConfigSetProjectDirectory)
+	if len(set) != 2 || set[1][1] == "" {
+		t.Errorf("notified %v, want an error for the missing directory", set)
+	}
+
+	result = lastResult(t, app, events.ConfigSelectProjectDirectory)
+	if result.ID != "3" || result.Error == nil || result.Error.Code != failure.Invalid {
+		t.Errorf("answered with %+v, want an invalid error for id 3", result)
+	}
+}
@@ -0,0 +1,275 @@
+// Package core is the business logic of proman, independent of the UI framework. A shell, such as the wails v1 app in
+// the repository root or the wails v2 app in wails2, loads an App, binds its services for the frontend and starts
+// them on its platform once the UI is ready.
+package core
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattouille/proman/dirs"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/instance"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+)
+
+// ErrForwarded is returned by Load when proman is already running and the launch was handed to it. The shell should
+// exit successfully.
+var ErrForwarded = errors.New("proman is already running, the request was handed to it")
+
+// classifies the errors of the services for the frontend
+func init() {
+	failure.Register(ErrProjectNotFound, failure.NotFound)
+	failure.Register(ErrNoEditor, failure.NotFound)
+	failure.Register(ErrNoForge, failure.Unavailable)
+	failure.Register(ErrNoSecretStore, failure.Unavailable)
+	failure.Register(ErrSetupIncomplete, failure.Conflict)
+	failure.Register(ErrActionNotFound, failure.NotFound)
+	failure.Register(ErrMalformedCommand, failure.Invalid)
+	failure.Register(ErrBlankCommand, failure.Invalid)
+	failure.Register(ErrSubProjectDir, failure.Invalid)
+	failure.Register(ErrNestedSubProject, failure.Invalid)
+	failure.Register(ErrNotSubProject, failure.Invalid)
+	failure.Register(ErrProjectExists, failure.Conflict)
+}
+
+// Service is a service of the core. Services are started by the shell once the UI is ready, see Start.
+type Service interface {
+	start(runtime *platform.Runtime) error
+}
+
+// stopper is implemented by services which hold resources until the UI closes.
+type stopper interface {
+	stop()
+}
+
+// Start starts service on runtime.
+func Start(service Service, runtime *platform.Runtime) error {
+	return service.start(runtime)
+}
+
+// Stop releases what service holds, if anything.
+func Stop(service Service) {
+	if s, ok := service.(stopper); ok {
+		s.stop()
+	}
+}
+
+// Options are the command line options of a launch.
+type Options struct {
+	// Config is the config file to use instead of the one of the active profile
+	Config string
+	// Open is the project to open
+	Open string
+	// Launcher shows the quick launcher, meant to be bound to a global shortcut
+	Launcher bool
+	// Action is the custom action to run against Project
+	Action string
+	// Project is the project Action runs against, the working directory when blank
+	Project string
+}
+
+// ParseArgs extracts proman's own flags from args. It returns the options and args without them, for the UI framework
+// to parse.
+func ParseArgs(args []string) (Options, []string) {
+	var opts Options
+
+	opts.Config, args = flagValue(args, "config")
+	opts.Open, args = flagValue(args, "open")
+	opts.Launcher, args = flagSet(args, "launcher")
+	opts.Action, args = flagValue(args, "action")
+	opts.Project, args = flagValue(args, "project")
+
+	return opts, args
+}
+
+// flagValue extracts the flag name, given as --name value, --name=value or with a single dash, from args. It returns
+// the value, blank when the flag isn't set, and args without the flag.
+func flagValue(args []string, name string) (string, []string) {
+	var (
+		value string
+		rest  = make([]string, 0, len(args))
+	)
+
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimPrefix(strings.TrimPrefix(args[i], "-"), "-")
+
+		switch {
+		case arg == name && args[i] != name:
+			if i+1 < len(args) {
+				value = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, name+"=") && args[i] != arg:
+			value = strings.TrimPrefix(arg, name+"=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	return value, rest
+}
+
+// flagSet extracts the boolean flag name, given as --name or with a single dash, from args. It reports whether the flag
+// was set and returns args without it.
+func flagSet(args []string, name string) (bool, []string) {
+	var (
+		set  bool
+		rest = make([]string, 0, len(args))
+	)
+
+	for _, arg := range args {
+		if arg == "-"+name || arg == "--"+name {
+			set = true
+
+			continue
+		}
+
+		rest = append(rest, arg)
+	}
+
+	return set, rest
+}
+
+// App holds the services of a launch, in the order they are started.
+type App struct {
+	Config       *Config
+	Validate     *Validate
+	Projects     *Projects
+	Setup        *Setup
+	Groups       *Groups
+	EditorConfig *EditorConfig
+	Profiles     *Profiles
+	Secrets      *Secrets
+	Actions      *Actions
+	Instance     *Instance
+}
+
+// Load prepares a launch. A later launch hands its request to the running instance, which holds the database, and
+// returns ErrForwarded. Otherwise the config of the active profile is read and its database opened.
+func Load(opts Options) (*App, error) {
+	var server *instance.Server
+
+	socket, err := socketPath()
+	if err == nil {
+		server, err = instance.Listen(socket)
+	}
+
+	if errors.Is(err, instance.ErrRunning) {
+		err = forward(socket, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrForwarded
+	}
+
+	// without a server this instance runs alongside others
+	if err != nil {
+		log.Printf("Unable to listen for other instances: %s", err)
+	}
+
+	file := opts.Config
+
+	profile, err := config.ActiveProfile()
+	if err == nil && file == "" {
+		file, err = config.ProfileConfigFile(profile)
+	}
+
+	// configuration automatically loads, but does need to be read in and checked for errors.
+	if err == nil {
+		err = config.ReadInConfig(file)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// start a new database connection
+	store, err := dataFile(profile, database.FileName)
+	if err == nil {
+		err = database.New(store, database.Options{})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// credentials are optional, projects still load without them
+	err = openSecrets()
+	if err != nil {
+		log.Printf("Unable to open secret store: %s", err)
+	}
+
+	cfg := NewConfig()
+	projects := NewProjects()
+	editors := NewEditorConfig()
+	actions := NewActions(projects)
+
+	return &App{
+		Config:       cfg,
+		Validate:     NewValidator(),
+		Projects:     projects,
+		Setup:        NewSetup(cfg, projects),
+		Groups:       NewGroups(projects),
+		EditorConfig: editors,
+		Profiles:     NewProfiles(cfg, projects, editors),
+		Secrets:      NewSecrets(),
+		Actions:      actions,
+		Instance:     NewInstance(server, projects, actions, opts),
+	}, nil
+}
+
+// Services lists the services of the app in the order they are started.
+func (a *App) Services() []Service {
+	return []Service{
+		a.Config, a.Validate, a.Projects, a.Setup, a.Groups, a.EditorConfig, a.Profiles, a.Secrets, a.Actions,
+		a.Instance,
+	}
+}
+
+// Start starts every service on runtime, stopping at the first which fails.
+func (a *App) Start(runtime *platform.Runtime) error {
+	for _, service := range a.Services() {
+		err := Start(service, runtime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops every service.
+func (a *App) Stop() {
+	for _, service := range a.Services() {
+		Stop(service)
+	}
+}
+
+// dataFile returns the path of the file name in the data directory of profile, which is created if needed. A file left
+// in the legacy location by an earlier version is moved to the default profile first.
+func dataFile(profile, name string) (string, error) {
+	dir, err := config.ProfileDataDir(profile)
+	if err != nil {
+		return "", err
+	}
+
+	if profile == config.DefaultProfile {
+		err = dirs.Migrate(name, dir)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err = dirs.Ensure(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
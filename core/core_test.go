@@ -0,0 +1,103 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/mattouille/proman/dirs"
+	"github.com/mattouille/proman/platform/fake"
+	"github.com/mattouille/proman/service/database"
+)
+
+// testApp is an App started on the fake platform, keeping its files in a temporary PROMAN_HOME.
+type testApp struct {
+	*App
+	platform *fake.Platform
+	// dir is the project directory
+	dir string
+}
+
+// setenv sets the environment variable key for the duration of the test.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	previous, ok := os.LookupEnv(key)
+
+	err := os.Setenv(key, value)
+	if err != nil {
+		t.Fatalf("unable to set %s: %s", key, err)
+	}
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, previous)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+// newProjectDir returns an empty project directory in a temporary PROMAN_HOME.
+func newProjectDir(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	setenv(t, dirs.HomeEnv, home)
+
+	dir := filepath.Join(home, "projects")
+
+	err := os.Mkdir(dir, 0o755)
+	if err != nil {
+		t.Fatalf("unable to create project directory: %s", err)
+	}
+
+	return dir
+}
+
+// mkdirs creates the directories, relative to dir.
+func mkdirs(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(name)), 0o755)
+		if err != nil {
+			t.Fatalf("unable to create %s: %s", name, err)
+		}
+	}
+}
+
+// startApp loads and starts the app with dir as project directory, config being appended to the config file. The app
+// is stopped, once its background scans are done, when the test ends.
+func startApp(t *testing.T, dir, config string) *testApp {
+	t.Helper()
+
+	file := filepath.Join(os.Getenv(dirs.HomeEnv), "config.toml")
+
+	err := os.WriteFile(file, []byte("project_directory = "+strconv.Quote(dir)+"\n"+config), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write config: %s", err)
+	}
+
+	app, err := Load(Options{Config: file})
+	if err != nil {
+		t.Fatalf("unable to load app: %s", err)
+	}
+
+	platform := fake.New()
+
+	t.Cleanup(func() {
+		app.Projects.background.Wait()
+		app.Stop()
+
+		_ = database.Service().Close()
+	})
+
+	err = app.Start(platform.Runtime())
+	if err != nil {
+		t.Fatalf("unable to start app: %s", err)
+	}
+
+	return &testApp{App: app, platform: platform, dir: dir}
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mattouille/proman/disk"
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/vcs"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// diskScanRetry is how long the periodic disk scan waits when the interval can't be read or isn't positive.
+const diskScanRetry = time.Hour
+
+// scanPeriodically scans disk usage again after every interval until quit is closed, so a long running app keeps its
+// measurements current. interval is asked for again before every wait, which picks up config edits.
+func (p *Projects) scanPeriodically(quit <-chan struct{}, interval func() (time.Duration, error)) {
+	defer p.scheduler.Done()
+
+	for {
+		wait, err := interval()
+		if err != nil || wait <= 0 {
+			p.log.ErrorFields("Unable to read disk scan interval", platform.Fields{"error": err, "interval": wait})
+
+			wait = diskScanRetry
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-quit:
+			timer.Stop()
+
+			return
+		case <-timer.C:
+			p.startScans(p.scanDiskUsage)
+		}
+	}
+}
+
+// diskScanInterval returns how long a disk usage measurement is reused, disk_scan_interval_hours.
+func diskScanInterval() (time.Duration, error) {
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(cfg.DiskScanIntervalHours) * time.Hour, nil
+}
+
+// scanDiskUsage measures every project whose cached measurement is stale, one at a time, storing each result in the
+// projects bucket and emitting a projects.disk_usage event as it completes. Only one scan runs at a time, it stops
+// before the next project once ctx is cancelled.
+func (p *Projects) scanDiskUsage(ctx context.Context) {
+	p.mu.Lock()
+	if p.scanning {
+		p.mu.Unlock()
+
+		return
+	}
+
+	p.scanning = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.scanning = false
+		p.mu.Unlock()
+	}()
+
+	interval, err := diskScanInterval()
+	if err != nil {
+		p.log.ErrorFields("Unable to read config for disk scan", platform.Fields{"error": err})
+
+		return
+	}
+
+	for _, project := range p.snapshot() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !p.needsMeasuring(project, interval) {
+			continue
+		}
+
+		_, err := p.measure(project.Path)
+		if err != nil {
+			p.log.DebugFields("Unable to measure project", platform.Fields{"path": project.Path, "error": err})
+		}
+	}
+}
+
+// needsMeasuring reports whether the project has no measurement, its measurement is older than interval, or the
+// project's directories changed since it was measured.
+func (p *Projects) needsMeasuring(project dto.Project, interval time.Duration) bool {
+	if project.DiskUsage == nil || time.Since(project.DiskUsage.ScannedAt) > interval {
+		return true
+	}
+
+	mod, err := disk.ModTime(p.absPath(project.Path))
+	if err != nil {
+		return false
+	}
+
+	return !mod.Equal(project.DiskUsage.ModTime)
+}
+
+// measure computes and stores the disk usage of the project at path.
+func (p *Projects) measure(path string) (dto.DiskUsage, error) {
+	abs := p.absPath(path)
+
+	repo, err := vcs.Open(abs)
+	if err != nil {
+		return dto.DiskUsage{}, err
+	}
+
+	usage, err := disk.Measure(abs, repo)
+	if err != nil {
+		return dto.DiskUsage{}, err
+	}
+
+	err = database.Service().UpsertProject(map[string]interface{}{"path": path, "disk_usage": &usage})
+	if err != nil {
+		return dto.DiskUsage{}, err
+	}
+
+	p.updateProject(path, func(project *dto.Project) {
+		project.DiskUsage = &usage
+	})
+
+	p.bus.Emit(events.ProjectsDiskUsage, path, usage)
+
+	return usage, nil
+}
+
+// DiskUsage returns the disk usage of the project at path, measuring it first if refresh is true or it has never been
+// measured.
+func (p *Projects) DiskUsage(path string, refresh bool) (_ dto.DiskUsage, err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := p.find(path)
+	if !ok {
+		return dto.DiskUsage{}, ErrProjectNotFound
+	}
+
+	if !refresh && project.DiskUsage != nil {
+		return *project.DiskUsage, nil
+	}
+
+	return p.measure(path)
+}
+
+// CleanBuildArtifacts removes the build and cache directories of the project at path. With dryRun set nothing is
+// removed and the result previews what would be.
+func (p *Projects) CleanBuildArtifacts(path string, dryRun bool) (_ dto.CleanResult, err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return dto.CleanResult{}, ErrProjectNotFound
+	}
+
+	abs := p.absPath(path)
+
+	repo, err := vcs.Open(abs)
+	if err != nil {
+		return dto.CleanResult{}, err
+	}
+
+	// always measure first so a stale cache can't point the clean at the wrong directories
+	usage, err := disk.Measure(abs, repo)
+	if err != nil {
+		return dto.CleanResult{}, err
+	}
+
+	result, err := disk.Clean(abs, repo, usage, dryRun)
+	result.Path = path
+
+	if err != nil {
+		return result, err
+	}
+
+	p.log.DebugFields("Cleaned build artifacts", platform.Fields{"path": path, "dry_run": dryRun, "freed": result.Freed})
+
+	if !dryRun {
+		_, err = p.measure(path)
+	}
+
+	return result, err
+}
+
+// find returns the loaded project at path.
+func (p *Projects) find(path string) (dto.Project, bool) {
+	for _, project := range p.snapshot() {
+		if project.Path == path {
+			return project, true
+		}
+	}
+
+	return dto.Project{}, false
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattouille/proman/events"
+)
+
+func TestScanPeriodically(t *testing.T) {
+	dir := newProjectDir(t)
+	commitRepo(t, filepath.Join(dir, "app"))
+
+	app := startApp(t, dir, "")
+	app.Projects.background.Wait()
+
+	measured := len(app.platform.Events.Emitted(events.ProjectsDiskUsage))
+	if measured != 1 {
+		t.Fatalf("%d measurements on start, want app measured once", measured)
+	}
+
+	// a changed directory makes the measurement stale long before disk_scan_interval_hours passes
+	src := filepath.Join(dir, "app", "src")
+	mkdirs(t, dir, "app/src")
+
+	later := time.Now().Add(time.Minute)
+
+	err := os.Chtimes(src, later, later)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	quit := make(chan struct{})
+	app.Projects.scheduler.Add(1)
+
+	go app.Projects.scanPeriodically(quit, func() (time.Duration, error) { return time.Millisecond, nil })
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for len(app.platform.Events.Emitted(events.ProjectsDiskUsage)) == measured {
+		if time.Now().After(deadline) {
+			t.Fatalf("app wasn't measured again")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// stopping the app waits for this scheduler along with its own
+	close(quit)
+}
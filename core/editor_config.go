@@ -0,0 +1,180 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/database"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+var ErrNoEditor = errors.New("no editor configured")
+
+func NewEditorConfig() *EditorConfig {
+	return new(EditorConfig)
+}
+
+type EditorConfig struct {
+	Editors []dto.Editor `json:"editors"`
+	runtime *platform.Runtime
+	log     platform.Logger
+	bus     *events.Bus
+	db      *database.DB
+}
+
+func (c *EditorConfig) start(runtime *platform.Runtime) error {
+	c.runtime = runtime
+	c.log = c.runtime.Log("config")
+	c.bus = events.NewBus(c.runtime.Events, c.log)
+	c.db = database.Service()
+
+	// preload the editors
+	_, err := c.GetAll(true)
+	if err != nil {
+		c.log.ErrorFields("Error while retrieving editors", platform.Fields{"error": err})
+		return err
+	}
+
+	c.registerEvents()
+
+	return nil
+}
+
+// Registers events which can be called via the wails runtime
+func (c *EditorConfig) registerEvents() {
+	c.bus.Handle(events.EditorUpsert, func(payload interface{}) error {
+		editor := payload.(dto.Editor)
+
+		return c.UpsertEditor(map[string]interface{}{
+			"name":    editor.Name,
+			"path":    editor.Path,
+			"icon":    editor.Icon,
+			"default": editor.Default,
+		})
+	})
+
+	c.bus.Handle(events.EditorRemove, func(payload interface{}) error {
+		return c.RemoveEditor(payload.(string))
+	})
+}
+
+func (c *EditorConfig) GetAll(refresh bool) (_ []dto.Editor, err error) {
+	defer failure.Wrap(&err)
+
+	if refresh {
+		editors, err := c.db.GetEditors()
+		if errors.Is(err, database.ErrNoRecords) {
+			editors = []dto.Editor{}
+		} else if err != nil {
+			return nil, err
+		}
+
+		c.Editors = editors
+	}
+
+	return c.Editors, nil
+}
+
+// reload refetches the editors after the database was switched.
+func (c *EditorConfig) reload() {
+	editors, err := c.db.GetEditors()
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		c.log.ErrorFields("Error while retrieving editors", platform.Fields{"error": err})
+	}
+
+	c.Editors = editors
+}
+
+func (c *EditorConfig) UpsertEditor(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	return c.db.UpsertEditor(data)
+}
+
+func (c *EditorConfig) RemoveEditor(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	return c.db.DeleteEditor(name)
+}
+
+// GetRules returns the editor rules.
+func (c *EditorConfig) GetRules() (_ []dto.EditorRule, err error) {
+	defer failure.Wrap(&err)
+
+	rules, err := c.db.GetEditorRules()
+	if errors.Is(err, database.ErrNoRecords) {
+		return []dto.EditorRule{}, nil
+	}
+
+	return rules, err
+}
+
+// UpsertRule creates or replaces an editor rule decoded from data.
+func (c *EditorConfig) UpsertRule(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	var rule dto.EditorRule
+
+	err = mapstructure.Decode(data, &rule)
+	if err != nil {
+		return fmt.Errorf("unable to decode editor rule: %w", err)
+	}
+
+	return c.db.UpsertEditorRule(rule)
+}
+
+// RemoveRule removes an editor rule by name.
+func (c *EditorConfig) RemoveRule(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	return c.db.DeleteEditorRule(name)
+}
+
+// preferredEditors lists well known editors for a language, most specific first. Names are matched against configured
+// editor names and binaries.
+var preferredEditors = map[string][]string{
+	"go":         {"goland", "code"},
+	"rust":       {"rustrover", "clion", "code"},
+	"python":     {"pycharm", "code"},
+	"java":       {"idea", "intellij", "code"},
+	"node":       {"webstorm", "code"},
+	"typescript": {"webstorm", "code"},
+	"php":        {"phpstorm", "code"},
+	"ruby":       {"rubymine", "code"},
+}
+
+// Suggest returns the configured editor best suited to the given language, falling back to the default editor.
+func (c *EditorConfig) Suggest(language string) (_ dto.Editor, err error) {
+	defer failure.Wrap(&err)
+
+	editors, err := c.GetAll(false)
+	if err != nil {
+		return dto.Editor{}, err
+	}
+
+	for _, preferred := range preferredEditors[strings.ToLower(language)] {
+		for _, editor := range editors {
+			name := strings.ToLower(editor.Name)
+			bin := strings.ToLower(filepath.Base(editor.Path))
+
+			if strings.Contains(name, preferred) || strings.HasPrefix(bin, preferred) {
+				return editor, nil
+			}
+		}
+	}
+
+	for _, editor := range editors {
+		if editor.Default {
+			return editor, nil
+		}
+	}
+
+	return dto.Editor{}, ErrNoEditor
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+)
+
+func TestEditors(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "")
+
+	for _, editor := range []map[string]interface{}{
+		{"name": "Vim", "path": "/usr/bin/vim", "default": true},
+		{"name": "GoLand", "path": "/opt/goland/bin/goland.sh"},
+	} {
+		err := app.EditorConfig.UpsertEditor(editor)
+		if err != nil {
+			t.Fatalf("unable to add %s: %s", editor["name"], err)
+		}
+	}
+
+	editors, err := app.EditorConfig.GetAll(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(editors) != 2 {
+		t.Fatalf("editors %v, want Vim and GoLand", editors)
+	}
+
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "go", want: "GoLand"},
+		{language: "Go", want: "GoLand"},
+		{language: "python", want: "Vim"},
+		{language: "", want: "Vim"},
+	}
+
+	for _, tt := range tests {
+		editor, err := app.EditorConfig.Suggest(tt.language)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", tt.language, err)
+		}
+
+		if editor.Name != tt.want {
+			t.Errorf("suggested %s for %q, want %s", editor.Name, tt.language, tt.want)
+		}
+	}
+
+	err = app.EditorConfig.RemoveEditor("Vim")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = app.EditorConfig.GetAll(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = app.EditorConfig.Suggest("python")
+	if !errors.Is(err, ErrNoEditor) {
+		t.Errorf("error %v without a default editor, want %v", err, ErrNoEditor)
+	}
+}
+
+func TestEditorEvents(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "")
+
+	app.platform.Events.Emit(events.EditorUpsert, dto.Editor{Name: "Vim", Path: "/usr/bin/vim"}, "1")
+
+	result := lastResult(t, app, events.EditorUpsert)
+	if result.ID != "1" || result.Error != nil {
+		t.Errorf("answered with %+v, want success for id 1", result)
+	}
+
+	app.platform.Events.Emit(events.EditorUpsert, dto.Editor{Name: "Vim"}, "2")
+
+	result = lastResult(t, app, events.EditorUpsert)
+	if result.ID != "2" || result.Error == nil || result.Error.Code != failure.Invalid {
+		t.Errorf("answered with %+v, want an invalid error for id 2", result)
+	}
+
+	app.platform.Events.Emit(events.EditorRemove, "Vim", "3")
+
+	result = lastResult(t, app, events.EditorRemove)
+	if result.ID != "3" || result.Error != nil {
+		t.Errorf("answered with %+v, want success for id 3", result)
+	}
+
+	editors, err := app.EditorConfig.GetAll(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(editors) != 0 {
+		t.Errorf("editors %v, want none", editors)
+	}
+}
+
+func TestEditorRules(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "")
+
+	rules, err := app.EditorConfig.GetRules()
+	if err != nil || len(rules) != 0 {
+		t.Fatalf("rules %v, %v, want none", rules, err)
+	}
+
+	err = app.EditorConfig.UpsertRule(map[string]interface{}{"name": "go", "editor": "GoLand", "language": "go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = app.EditorConfig.UpsertRule(map[string]interface{}{"name": "go", "editor": "Vim", "language": "go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules, err = app.EditorConfig.GetRules()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := dto.EditorRule{Name: "go", Editor: "Vim", Language: "go"}
+	if len(rules) != 1 || rules[0] != want {
+		t.Errorf("rules %+v, want the replaced rule %+v", rules, want)
+	}
+
+	err = app.EditorConfig.RemoveRule("go")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules, err = app.EditorConfig.GetRules()
+	if err != nil || len(rules) != 0 {
+		t.Errorf("rules %v, %v, want none", rules, err)
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/path"
+)
+
+// resolveEditor picks the editor a project is opened with. An explicit OpenWith on the project always wins, either
+// naming a configured editor or a binary. Otherwise the most specific matching rule applies, ties going to the rule
+// whose name sorts first, and finally the default editor is used.
+func resolveEditor(project dto.Project, abs string, rules []dto.EditorRule, editors []dto.Editor) (dto.Editor, error) {
+	if project.OpenWith != "" {
+		if editor, ok := editorByName(editors, project.OpenWith); ok {
+			return editor, nil
+		}
+
+		return dto.Editor{Name: project.OpenWith, Path: project.OpenWith}, nil
+	}
+
+	matched := make([]dto.EditorRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if ruleMatches(rule, project, abs) {
+			matched = append(matched, rule)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Specificity() != matched[j].Specificity() {
+			return matched[i].Specificity() > matched[j].Specificity()
+		}
+
+		// between two roots the deeper one is more specific
+		if len(matched[i].Root) != len(matched[j].Root) {
+			return len(matched[i].Root) > len(matched[j].Root)
+		}
+
+		return matched[i].Name < matched[j].Name
+	})
+
+	for _, rule := range matched {
+		// rules pointing at a removed editor are skipped rather than breaking project opening
+		if editor, ok := editorByName(editors, rule.Editor); ok {
+			return editor, nil
+		}
+	}
+
+	for _, editor := range editors {
+		if editor.Default {
+			return editor, nil
+		}
+	}
+
+	return dto.Editor{}, ErrNoEditor
+}
+
+// ruleMatches reports whether every criterion set on the rule matches the project. A rule without criteria never
+// matches.
+func ruleMatches(rule dto.EditorRule, project dto.Project, abs string) bool {
+	if rule.Specificity() == 0 {
+		return false
+	}
+
+	if rule.Language != "" && !hasLanguage(project, rule.Language) {
+		return false
+	}
+
+	if rule.Tag != "" && !hasTags(project, []string{rule.Tag}) {
+		return false
+	}
+
+	if rule.Root != "" {
+		root, err := path.Expand(rule.Root)
+		if err != nil || !isWithin(root, abs) {
+			return false
+		}
+	}
+
+	if rule.Glob != "" && !globMatches(rule.Glob, project.Path, abs) {
+		return false
+	}
+
+	return true
+}
+
+// globMatches matches pattern against the absolute project path, or the path from the project directory when the
+// pattern is relative.
+func globMatches(pattern, rel, abs string) bool {
+	target := rel
+
+	if pattern == "~" || strings.HasPrefix(pattern, "~/") || filepath.IsAbs(pattern) {
+		expanded, err := path.Expand(pattern)
+		if err != nil {
+			return false
+		}
+
+		pattern = expanded
+		target = abs
+	}
+
+	ok, err := filepath.Match(pattern, target)
+
+	return err == nil && ok
+}
+
+func hasLanguage(project dto.Project, language string) bool {
+	for _, lang := range project.Languages {
+		if strings.EqualFold(lang.Name, language) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func editorByName(editors []dto.Editor, name string) (dto.Editor, bool) {
+	for _, editor := range editors {
+		if strings.EqualFold(editor.Name, name) {
+			return editor, true
+		}
+	}
+
+	return dto.Editor{}, false
+}
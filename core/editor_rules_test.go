@@ -0,0 +1,148 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+)
+
+func TestResolveEditor(t *testing.T) {
+	editors := []dto.Editor{
+		{Name: "code", Path: "/usr/bin/code", Default: true},
+		{Name: "vim", Path: "/usr/bin/vim"},
+		{Name: "goland", Path: "/opt/goland/bin/goland"},
+		{Name: "idea", Path: "/opt/idea/bin/idea"},
+		{Name: "zed", Path: "/usr/bin/zed"},
+	}
+
+	project := dto.Project{
+		Path:      "api",
+		Tags:      []string{"client"},
+		Languages: []dto.Language{{Name: "go"}},
+	}
+
+	abs := "/src/work/api"
+
+	tests := []struct {
+		name     string
+		openWith string
+		rules    []dto.EditorRule
+		editors  []dto.Editor
+		want     string
+		err      error
+	}{
+		{
+			name:     "open with wins over every rule",
+			openWith: "vim",
+			rules: []dto.EditorRule{
+				{Name: "glob", Editor: "zed", Glob: "api"},
+				{Name: "root", Editor: "idea", Root: "/src"},
+				{Name: "tag", Editor: "goland", Tag: "client"},
+				{Name: "language", Editor: "goland", Language: "go"},
+			},
+			want: "vim",
+		},
+		{
+			name:     "open with an unconfigured binary",
+			openWith: "/usr/local/bin/nano",
+			want:     "/usr/local/bin/nano",
+		},
+		{
+			name: "glob over root",
+			rules: []dto.EditorRule{
+				{Name: "root", Editor: "idea", Root: "/src"},
+				{Name: "glob", Editor: "zed", Glob: "/src/*/api"},
+			},
+			want: "zed",
+		},
+		{
+			name: "root over tag",
+			rules: []dto.EditorRule{
+				{Name: "tag", Editor: "goland", Tag: "client"},
+				{Name: "root", Editor: "idea", Root: "/src"},
+			},
+			want: "idea",
+		},
+		{
+			name: "tag over language",
+			rules: []dto.EditorRule{
+				{Name: "language", Editor: "vim", Language: "go"},
+				{Name: "tag", Editor: "goland", Tag: "client"},
+			},
+			want: "goland",
+		},
+		{
+			name: "combined criteria add up",
+			rules: []dto.EditorRule{
+				{Name: "root", Editor: "idea", Root: "/src"},
+				{Name: "root language", Editor: "goland", Root: "/src", Language: "go"},
+			},
+			want: "goland",
+		},
+		{
+			name: "deeper root wins",
+			rules: []dto.EditorRule{
+				{Name: "a", Editor: "idea", Root: "/src"},
+				{Name: "b", Editor: "zed", Root: "/src/work"},
+			},
+			want: "zed",
+		},
+		{
+			name: "name breaks ties",
+			rules: []dto.EditorRule{
+				{Name: "b", Editor: "vim", Language: "go"},
+				{Name: "a", Editor: "goland", Language: "go"},
+			},
+			want: "goland",
+		},
+		{
+			name: "rules which don't match are ignored",
+			rules: []dto.EditorRule{
+				{Name: "python", Editor: "vim", Language: "python"},
+				{Name: "elsewhere", Editor: "idea", Root: "/home"},
+				{Name: "empty", Editor: "zed"},
+			},
+			want: "code",
+		},
+		{
+			name: "missing editor falls back to the next rule",
+			rules: []dto.EditorRule{
+				{Name: "glob", Editor: "emacs", Glob: "api"},
+				{Name: "tag", Editor: "goland", Tag: "client"},
+			},
+			want: "goland",
+		},
+		{
+			name:  "missing editor falls back to the default",
+			rules: []dto.EditorRule{{Name: "glob", Editor: "emacs", Glob: "api"}},
+			want:  "code",
+		},
+		{
+			name:    "no default editor",
+			editors: []dto.Editor{{Name: "vim", Path: "/usr/bin/vim"}},
+			err:     ErrNoEditor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := project
+			p.OpenWith = tt.openWith
+
+			configured := tt.editors
+			if configured == nil {
+				configured = editors
+			}
+
+			editor, err := resolveEditor(p, abs, tt.rules, configured)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+
+			if editor.Name != tt.want {
+				t.Errorf("resolved %q, want %q", editor.Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/forge"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/service/secrets"
+)
+
+var ErrNoForge = errors.New("project has no remote on a known forge")
+
+// forges returns the configured forges merged over the built in ones.
+func (p *Projects) forges() []dto.ForgeConfig {
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		p.log.ErrorFields("Unable to read forge config", platform.Fields{"error": err})
+
+		return forge.Defaults
+	}
+
+	return forge.Forges(cfg.Forges)
+}
+
+// repository returns the forge repository of the first recognised remote of the project.
+func (p *Projects) repository(project dto.Project) (forge.Repository, bool) {
+	forges := p.forges()
+
+	for _, remote := range project.Remotes {
+		repo, ok := forge.Parse(remote, forges)
+		if ok {
+			return repo, true
+		}
+	}
+
+	return forge.Repository{}, false
+}
+
+// enrichRemotes fetches forge metadata for every project with a recognised remote, storing it on the project and
+// emitting a projects.remote event as each completes. Only one run happens at a time, it stops before the next project
+// once ctx is cancelled.
+func (p *Projects) enrichRemotes(ctx context.Context) {
+	p.mu.Lock()
+	if p.enriching {
+		p.mu.Unlock()
+
+		return
+	}
+
+	p.enriching = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.enriching = false
+		p.mu.Unlock()
+	}()
+
+	for _, project := range p.snapshot() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		_, err := p.RemoteMetadata(project.Path, false)
+		if err != nil && !errors.Is(err, ErrNoForge) {
+			p.log.DebugFields("Unable to fetch remote metadata", platform.Fields{"path": project.Path, "error": err})
+		}
+	}
+}
+
+// RemoteMetadata returns forge metadata for the project at path. Cached metadata is used until it expires or refresh
+// is true. When the forge can't be reached the expired cache entry is returned marked as stale, so the app keeps
+// working offline.
+func (p *Projects) RemoteMetadata(path string, refresh bool) (_ dto.RemoteMetadata, err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := p.find(path)
+	if !ok {
+		return dto.RemoteMetadata{}, ErrProjectNotFound
+	}
+
+	repo, ok := p.repository(project)
+	if !ok {
+		return dto.RemoteMetadata{}, ErrNoForge
+	}
+
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	ttl := time.Duration(cfg.ForgeCacheTTLMinutes) * time.Minute
+
+	cached, err := database.Service().GetForgeMetadata(repo.Key())
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return dto.RemoteMetadata{}, err
+	}
+
+	hasCache := err == nil
+
+	if hasCache && !refresh && time.Since(cached.FetchedAt) < ttl {
+		return cached, p.storeRemote(path, cached)
+	}
+
+	meta, err := p.fetchRemote(repo)
+	if err != nil {
+		if !hasCache {
+			return dto.RemoteMetadata{}, err
+		}
+
+		p.log.DebugFields("Using stale remote metadata", platform.Fields{"path": path, "error": err})
+
+		cached.Stale = true
+
+		return cached, p.storeRemote(path, cached)
+	}
+
+	err = database.Service().PutForgeMetadata(repo.Key(), meta)
+	if err != nil {
+		p.log.ErrorFields("Unable to cache remote metadata", platform.Fields{"path": path, "error": err})
+	}
+
+	return meta, p.storeRemote(path, meta)
+}
+
+func (p *Projects) fetchRemote(repo forge.Repository) (dto.RemoteMetadata, error) {
+	// tokens are only kept in the secret store, requests are anonymous while it is locked
+	repo.Forge.Token, _ = secrets.Lookup(secrets.ForgeTokenKey(repo.Forge.Host))
+
+	provider, err := forge.NewProvider(repo.Forge, nil)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forge.DefaultTimeout)
+	defer cancel()
+
+	meta, err := provider.Repository(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	meta.FetchedAt = time.Now()
+
+	return meta, nil
+}
+
+// storeRemote saves metadata on the project at path and notifies the frontend. Metadata the project already holds is
+// not written again.
+func (p *Projects) storeRemote(path string, meta dto.RemoteMetadata) error {
+	if project, ok := p.find(path); ok && project.Remote != nil && *project.Remote == meta {
+		return nil
+	}
+
+	err := database.Service().UpsertProject(map[string]interface{}{"path": path, "remote": &meta})
+	if err != nil {
+		return err
+	}
+
+	p.updateProject(path, func(project *dto.Project) {
+		project.Remote = &meta
+	})
+
+	p.bus.Emit(events.ProjectsRemote, path, meta)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+
+	"github.com/mattouille/proman/events"
+)
+
+func TestRemoteMetadataCache(t *testing.T) {
+	var requests, failing int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		if r.URL.Path != "/repos/proman/app" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"description": "projects", "default_branch": "main"}`))
+	}))
+	defer server.Close()
+
+	dir := newProjectDir(t)
+
+	repo, err := git.PlainInit(filepath.Join(dir, "app"), false)
+	if err == nil {
+		_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"https://forge.test/proman/app"}})
+	}
+
+	if err != nil {
+		t.Fatalf("unable to create repository: %s", err)
+	}
+
+	app := startApp(t, dir, "[[forges]]\nhost = \"forge.test\"\nkind = \"gitea\"\napi_url = \""+server.URL+"\"\n")
+
+	// the metadata is fetched once in the background on start
+	app.Projects.background.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("%d requests on start, want 1", n)
+	}
+
+	if n := len(app.platform.Events.Emitted(events.ProjectsRemote)); n != 1 {
+		t.Errorf("%d %s events, want 1", n, events.ProjectsRemote)
+	}
+
+	meta, err := app.Projects.RemoteMetadata("app", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if meta.Description != "projects" || meta.Stale {
+		t.Errorf("cached metadata %+v", meta)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("%d requests, want the cached metadata to be used", n)
+	}
+
+	_, err = app.Projects.RemoteMetadata("app", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d requests, want a refresh to fetch again", n)
+	}
+
+	atomic.StoreInt32(&failing, 1)
+
+	meta, err = app.Projects.RemoteMetadata("app", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if meta.Description != "projects" || !meta.Stale {
+		t.Errorf("metadata %+v, want the cached metadata marked stale", meta)
+	}
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/database"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func NewGroups(projects *Projects) *Groups {
+	return &Groups{projects: projects}
+}
+
+// Groups is the frontend service for project groups and saved views.
+type Groups struct {
+	runtime  *platform.Runtime
+	log      platform.Logger
+	bus      *events.Bus
+	db       *database.DB
+	projects *Projects
+}
+
+func (g *Groups) start(runtime *platform.Runtime) error {
+	g.runtime = runtime
+	g.log = g.runtime.Log("groups")
+	g.bus = events.NewBus(g.runtime.Events, g.log)
+	g.db = database.Service()
+
+	return nil
+}
+
+// GetGroups returns all project groups.
+func (g *Groups) GetGroups() (_ []dto.Group, err error) {
+	defer failure.Wrap(&err)
+
+	groups, err := g.db.GetGroups()
+	if errors.Is(err, database.ErrNoRecords) {
+		return []dto.Group{}, nil
+	}
+
+	return groups, err
+}
+
+// UpsertGroup creates or replaces a group decoded from data.
+func (g *Groups) UpsertGroup(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	var group dto.Group
+
+	err = mapstructure.Decode(data, &group)
+	if err != nil {
+		return fmt.Errorf("unable to decode group: %w", err)
+	}
+
+	return g.changed(g.db.UpsertGroup(group))
+}
+
+// RemoveGroup removes a group by name. Its member projects are left untouched.
+func (g *Groups) RemoveGroup(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	return g.changed(g.db.DeleteGroup(name))
+}
+
+// AddToGroup adds the project at path to a group, creating the group if it doesn't exist.
+func (g *Groups) AddToGroup(name, path string) (err error) {
+	defer failure.Wrap(&err)
+
+	group, err := g.db.GetGroup(name)
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return err
+	}
+
+	group.Name = name
+
+	for _, member := range group.Projects {
+		if member == path {
+			return nil
+		}
+	}
+
+	group.Projects = append(group.Projects, path)
+
+	return g.changed(g.db.UpsertGroup(group))
+}
+
+// RemoveFromGroup removes the project at path from a group.
+func (g *Groups) RemoveFromGroup(name, path string) (err error) {
+	defer failure.Wrap(&err)
+
+	group, err := g.db.GetGroup(name)
+	if err != nil {
+		return err
+	}
+
+	members := group.Projects[:0]
+
+	for _, member := range group.Projects {
+		if member != path {
+			members = append(members, member)
+		}
+	}
+
+	group.Projects = members
+
+	return g.changed(g.db.UpsertGroup(group))
+}
+
+// GroupProjects returns the known projects in a group. Members which no longer exist are skipped.
+func (g *Groups) GroupProjects(name string) (_ []dto.Project, err error) {
+	defer failure.Wrap(&err)
+
+	group, err := g.db.GetGroup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []dto.Project
+
+	for _, member := range group.Projects {
+		project, ok := g.projects.find(member)
+		if !ok {
+			g.log.DebugFields("Skipping missing group member", platform.Fields{"group": name, "path": member})
+
+			continue
+		}
+
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// GetViews returns all saved views.
+func (g *Groups) GetViews() (_ []dto.SavedView, err error) {
+	defer failure.Wrap(&err)
+
+	views, err := g.db.GetViews()
+	if errors.Is(err, database.ErrNoRecords) {
+		return []dto.SavedView{}, nil
+	}
+
+	return views, err
+}
+
+// UpsertView creates or replaces a saved view decoded from data.
+func (g *Groups) UpsertView(data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	var view dto.SavedView
+
+	err = mapstructure.Decode(data, &view)
+	if err != nil {
+		return fmt.Errorf("unable to decode view: %w", err)
+	}
+
+	return g.changed(g.db.UpsertView(view))
+}
+
+// RemoveView removes a saved view by name.
+func (g *Groups) RemoveView(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	return g.changed(g.db.DeleteView(name))
+}
+
+// ViewProjects runs the search stored in a saved view and returns the matching projects.
+func (g *Groups) ViewProjects(name string) (_ []dto.Project, err error) {
+	defer failure.Wrap(&err)
+
+	view, err := g.db.GetView(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.projects.Select(view.Selector)
+}
+
+// changed emits groups.changed so the menu can reload after a successful mutation. err is passed through.
+func (g *Groups) changed(err error) error {
+	if err == nil {
+		g.bus.Emit(events.GroupsChanged)
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/vcs"
+)
+
+// units of the config values given in days and megabytes
+const (
+	day      = 24 * time.Hour
+	megabyte = 1 << 20
+)
+
+// Health returns a hygiene report for every known project. Cached reports are returned unless refresh is true, in
+// which case every repository is checked again and the cache is replaced.
+func (p *Projects) Health(refresh bool) (_ []dto.HealthReport, err error) {
+	defer failure.Wrap(&err)
+
+	if !refresh {
+		reports, err := database.Service().GetHealthReports()
+		if err == nil || !errors.Is(err, database.ErrNoRecords) {
+			return reports, err
+		}
+	}
+
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := vcs.HealthOptions{
+		StaleAfter:      time.Duration(cfg.HealthStaleDays) * day,
+		StashStaleAfter: time.Duration(cfg.HealthStashDays) * day,
+		LargeFileSize:   int64(cfg.HealthLargeFileMB) * megabyte,
+		Now:             time.Now(),
+	}
+
+	projects := p.snapshot()
+	reports := make([]dto.HealthReport, 0, len(projects))
+
+	for _, project := range projects {
+		// a sub-project is checked as part of the repository of its parent
+		if project.Parent != "" {
+			continue
+		}
+
+		report := p.checkHealth(project, opts)
+
+		err := database.Service().PutHealthReport(report)
+		if err != nil {
+			p.log.ErrorFields("Unable to cache health report", platform.Fields{"path": project.Path, "error": err})
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// HealthJSON returns the hygiene report as indented JSON.
+func (p *Projects) HealthJSON(refresh bool) (_ string, err error) {
+	defer failure.Wrap(&err)
+
+	reports, err := p.Health(refresh)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+func (p *Projects) checkHealth(project dto.Project, opts vcs.HealthOptions) dto.HealthReport {
+	report := dto.HealthReport{Path: project.Path, CheckedAt: opts.Now}
+
+	abs := p.absPath(project.Path)
+
+	repo, err := vcs.Open(abs)
+	if err != nil {
+		report.Error = err.Error()
+
+		return report
+	}
+
+	report.Repository = repo != nil
+
+	report.Issues, err = vcs.Health(abs, repo, opts)
+	if err != nil {
+		p.log.DebugFields("Health check failed", platform.Fields{"path": project.Path, "error": err})
+
+		report.Error = err.Error()
+	}
+
+	return report
+}
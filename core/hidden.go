@@ -0,0 +1,71 @@
+package core
+
+import (
+	"path/filepath"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+)
+
+// SetHidden hides or reveals the project at path on the project list.
+func (p *Projects) SetHidden(path string, hidden bool) (err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return ErrProjectNotFound
+	}
+
+	err = database.Service().UpsertProject(map[string]interface{}{"path": path, "hide": hidden})
+	if err != nil {
+		return err
+	}
+
+	p.updateProject(path, func(project *dto.Project) {
+		project.Hide = hidden
+	})
+
+	return nil
+}
+
+// filterHidden marks hidden projects and, unless include is true, removes them. projects is filtered in place.
+func (p *Projects) filterHidden(projects []dto.Project, include bool) ([]dto.Project, error) {
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return nil, err
+	}
+
+	visible := projects[:0]
+
+	for _, project := range projects {
+		project.Hidden = project.Hide || p.matchesHiddenPattern(project.Path, cfg.HiddenPatterns)
+
+		if project.Hidden && !include {
+			continue
+		}
+
+		visible = append(visible, project)
+	}
+
+	return visible, nil
+}
+
+// matchesHiddenPattern reports whether the project path matches any of the hidden glob patterns.
+func (p *Projects) matchesHiddenPattern(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		ok, err := filepath.Match(pattern, path)
+		if err != nil {
+			p.log.DebugFields("Invalid hidden pattern", platform.Fields{"pattern": pattern, "error": err})
+
+			continue
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+)
+
+// frecencyBuckets weight opens by how long ago they happened, most recent first. An open older than every bucket
+// is worth frecencyFloor.
+var frecencyBuckets = []struct {
+	age    time.Duration
+	weight float64
+}{
+	{4 * day, 100},
+	{14 * day, 70},
+	{31 * day, 50},
+	{90 * day, 30},
+}
+
+const frecencyFloor = 10
+
+// frecency scores a project by how often and how recently it was opened.
+func frecency(events []dto.OpenEvent, now time.Time) float64 {
+	score := 0.0
+
+	for _, event := range events {
+		weight := float64(frecencyFloor)
+
+		for _, bucket := range frecencyBuckets {
+			if now.Sub(event.OpenedAt) <= bucket.age {
+				weight = bucket.weight
+
+				break
+			}
+		}
+
+		score += weight
+	}
+
+	return score
+}
+
+// lastOpened returns when the project was most recently opened, or the zero time if it never was.
+func lastOpened(events []dto.OpenEvent) time.Time {
+	var last time.Time
+
+	for _, event := range events {
+		if event.OpenedAt.After(last) {
+			last = event.OpenedAt
+		}
+	}
+
+	return last
+}
+
+// recordOpen stores an open of the project at path with editor.
+func (p *Projects) recordOpen(path string, editor dto.Editor) {
+	err := database.Service().RecordOpen(dto.OpenEvent{Path: path, Editor: editor.Name, OpenedAt: time.Now()})
+	if err != nil {
+		p.log.ErrorFields("Unable to record project open", platform.Fields{"path": path, "error": err})
+	}
+}
+
+// history returns the open history of every project.
+func (p *Projects) history() (map[string][]dto.OpenEvent, error) {
+	history, err := database.Service().GetHistory()
+	if errors.Is(err, database.ErrNoRecords) {
+		return map[string][]dto.OpenEvent{}, nil
+	}
+
+	return history, err
+}
+
+// sortProjects orders projects in place by the given sort option.
+func (p *Projects) sortProjects(projects []dto.Project, by string) error {
+	switch by {
+	case "":
+		return nil
+	case dto.SortName:
+		sort.SliceStable(projects, func(i, j int) bool { return projects[i].Path < projects[j].Path })
+
+		return nil
+	case dto.SortFrecency, dto.SortRecent:
+	default:
+		return fmt.Errorf("unknown sort option: %s", by)
+	}
+
+	history, err := p.history()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := history[projects[i].Path], history[projects[j].Path]
+
+		if by == dto.SortRecent {
+			return lastOpened(a).After(lastOpened(b))
+		}
+
+		return frecency(a, now) > frecency(b, now)
+	})
+
+	return nil
+}
+
+// Recent returns up to n of the most recently opened projects, most recent first. Projects which were never opened
+// and hidden projects are left out.
+func (p *Projects) Recent(n int) (_ []dto.Project, err error) {
+	defer failure.Wrap(&err)
+
+	history, err := p.history()
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := p.filterHidden(p.snapshot(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []dto.Project
+
+	for _, project := range projects {
+		if len(history[project.Path]) > 0 {
+			recent = append(recent, project)
+		}
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return lastOpened(history[recent[i].Path]).After(lastOpened(history[recent[j].Path]))
+	})
+
+	if n >= 0 && len(recent) > n {
+		recent = recent[:n]
+	}
+
+	return recent, nil
+}
+
+// PruneHistory deletes opens older than history_max_age_days, unless it is 0, and all but the newest
+// history_max_entries opens of each project. It returns the number of opens deleted.
+func (p *Projects) PruneHistory() (_ int, err error) {
+	defer failure.Wrap(&err)
+
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return 0, err
+	}
+
+	// a zero time keeps opens of any age
+	before := time.Time{}
+	if cfg.HistoryMaxAgeDays > 0 {
+		before = time.Now().Add(-time.Duration(cfg.HistoryMaxAgeDays) * day)
+	}
+
+	deleted, err := database.Service().PruneHistory(before, cfg.HistoryMaxEntries)
+	if err != nil {
+		return deleted, err
+	}
+
+	p.log.DebugFields("Pruned project history", platform.Fields{"deleted": deleted})
+
+	return deleted, nil
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/service/database"
+)
+
+func TestPruneHistory(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "app")
+
+	app := startApp(t, dir, "history_max_age_days = 30\nhistory_max_entries = 10\n")
+
+	for _, age := range []time.Duration{time.Hour, 60 * day, 3650 * day} {
+		err := database.Service().RecordOpen(dto.OpenEvent{Path: "app", OpenedAt: time.Now().Add(-age)})
+		if err != nil {
+			t.Fatalf("unable to record open: %s", err)
+		}
+	}
+
+	// 0 keeps opens of any age, pruning with it deletes nothing
+	err := app.Config.Update(map[string]interface{}{"history_max_age_days": 0})
+	if err != nil {
+		t.Fatalf("unable to keep history of any age: %s", err)
+	}
+
+	deleted, err := app.Projects.PruneHistory()
+	if err != nil || deleted != 0 {
+		t.Fatalf("deleted %d, %v, want every open kept", deleted, err)
+	}
+
+	err = app.Config.Update(map[string]interface{}{"history_max_age_days": 30})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	deleted, err = app.Projects.PruneHistory()
+	if err != nil || deleted != 2 {
+		t.Errorf("deleted %d, %v, want the opens older than 30 days", deleted, err)
+	}
+}
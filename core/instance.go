@@ -0,0 +1,227 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattouille/proman/dirs"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/instance"
+	"github.com/mattouille/proman/platform"
+)
+
+// socketPath returns the path of the socket the running instance listens on.
+func socketPath() (string, error) {
+	dir, err := dirs.State()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = dirs.Ensure(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, instance.SocketName), nil
+}
+
+// forward sends the request of this launch to the running instance: run the action opts.Action, open the project
+// opts.Open, show the quick launcher, or focus when none was asked for.
+func forward(socket string, opts Options) error {
+	req := instance.Request{Command: instance.CommandFocus}
+
+	if opts.Launcher {
+		req = instance.Request{Command: instance.CommandLauncher}
+	}
+
+	if opts.Open != "" {
+		abs, err := filepath.Abs(opts.Open)
+		if err != nil {
+			return err
+		}
+
+		req = instance.Request{Command: instance.CommandOpen, Path: abs}
+	}
+
+	if opts.Action != "" {
+		abs, err := actionProject(opts)
+		if err != nil {
+			return err
+		}
+
+		req = instance.Request{Command: instance.CommandAction, Path: abs, Action: opts.Action}
+	}
+
+	return instance.Send(socket, req)
+}
+
+// actionProject returns the absolute path of the project the action of opts is run against, which defaults to the
+// working directory.
+func actionProject(opts Options) (string, error) {
+	if opts.Project == "" {
+		return os.Getwd()
+	}
+
+	return filepath.Abs(opts.Project)
+}
+
+func NewInstance(server *instance.Server, projects *Projects, actions *Actions, opts Options) *Instance {
+	return &Instance{server: server, projects: projects, actions: actions, opts: opts}
+}
+
+// Instance serves requests forwarded by later launches of proman, and tells the frontend what this launch was asked
+// for.
+type Instance struct {
+	runtime  *platform.Runtime
+	log      platform.Logger
+	bus      *events.Bus
+	server   *instance.Server
+	projects *Projects
+	actions  *Actions
+	// opts are what this launch was asked for
+	opts Options
+}
+
+// Launcher reports whether this launch was asked for the quick launcher, which the frontend then shows instead of the
+// project list.
+func (i *Instance) Launcher() bool {
+	return i.opts.Launcher
+}
+
+func (i *Instance) start(runtime *platform.Runtime) error {
+	i.runtime = runtime
+	i.log = i.runtime.Log("instance")
+	i.bus = events.NewBus(i.runtime.Events, i.log)
+
+	if i.opts.Open != "" {
+		go i.openProject()
+	}
+
+	if i.opts.Action != "" {
+		go i.runAction()
+	}
+
+	// without a server this instance runs alongside others
+	if i.server != nil {
+		go i.server.Serve(i.handle)
+	}
+
+	return nil
+}
+
+// openProject opens the project this launch was asked for. Like a forwarded path, it is made absolute first, since it
+// is relative to the working directory of the launch rather than the project directory.
+func (i *Instance) openProject() {
+	path, err := filepath.Abs(i.opts.Open)
+	if err == nil {
+		err = i.handle(instance.Request{Command: instance.CommandOpen, Path: path})
+	}
+
+	if err != nil {
+		i.log.ErrorFields("Unable to open project", platform.Fields{"path": i.opts.Open, "error": err})
+	}
+}
+
+// runAction runs the action this launch was asked for.
+func (i *Instance) runAction() {
+	path, err := actionProject(i.opts)
+	if err == nil {
+		err = i.handle(instance.Request{Command: instance.CommandAction, Path: path, Action: i.opts.Action})
+	}
+
+	if err != nil {
+		i.log.ErrorFields("Unable to run action", platform.Fields{"action": i.opts.Action, "error": err})
+	}
+}
+
+func (i *Instance) stop() {
+	if i.server == nil {
+		return
+	}
+
+	err := i.server.Close()
+	if err != nil {
+		i.log.ErrorFields("Unable to close instance socket", platform.Fields{"error": err})
+	}
+}
+
+func (i *Instance) handle(req instance.Request) error {
+	i.log.DebugFields("Handling request from another instance", platform.Fields{
+		"command": req.Command,
+		"path":    req.Path,
+		"action":  req.Action,
+	})
+
+	switch req.Command {
+	case instance.CommandFocus:
+		// wails can't raise its window, the frontend asks the webview for focus instead
+		i.bus.Emit(events.InstanceFocus)
+
+		return nil
+	case instance.CommandLauncher:
+		i.bus.Emit(events.LauncherShow)
+
+		return nil
+	case instance.CommandOpen:
+		path, err := i.projectPath(req.Path)
+		if err != nil {
+			return err
+		}
+
+		i.bus.Emit(events.InstanceFocus)
+
+		err = i.projects.OpenProject(path)
+		if err != nil {
+			// the launching process prints the message, not the description meant for the frontend
+			return errors.New(failure.Describe(err).Message)
+		}
+
+		return nil
+	case instance.CommandAction:
+		path, err := i.projectPath(req.Path)
+		if err != nil {
+			return err
+		}
+
+		err = i.actions.Run(path, req.Action)
+		if err != nil {
+			return errors.New(failure.Describe(err).Message)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", instance.ErrUnknownCommand, req.Command)
+	}
+}
+
+// projectPath converts an absolute path inside the project directory into the path of the project containing it, the
+// sub-project when there is one. Other paths are taken to be project paths already.
+func (i *Instance) projectPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	i.projects.mu.RLock()
+	root := i.projects.root
+	i.projects.mu.RUnlock()
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", ErrProjectNotFound
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	for _, project := range i.projects.snapshot() {
+		if project.Parent != "" && (rel == project.Path || strings.HasPrefix(rel, project.Path+"/")) {
+			return project.Path, nil
+		}
+	}
+
+	// the first element is the project, the rest a directory inside it
+	return strings.Split(rel, "/")[0], nil
+}
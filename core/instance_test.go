@@ -0,0 +1,35 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattouille/proman/events"
+)
+
+func TestOpenProjectRelative(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "app/src")
+
+	app := startApp(t, dir, "")
+
+	wd, err := os.Getwd()
+	if err == nil {
+		err = os.Chdir(filepath.Join(dir, "app", "src"))
+	}
+
+	if err != nil {
+		t.Fatalf("unable to change directory: %s", err)
+	}
+
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	// the path is relative to where proman was launched, not to the project directory
+	app.Instance.opts.Open = "."
+	app.Instance.openProject()
+
+	if n := len(app.platform.Events.Emitted(events.InstanceFocus)); n != 1 {
+		t.Errorf("focused %d times, want the window focused for app", n)
+	}
+}
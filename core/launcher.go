@@ -0,0 +1,87 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/service/database"
+)
+
+// launcherLimit is the most projects the quick launcher lists.
+const launcherLimit = 20
+
+// SetPinned pins the project at path to the top of the quick launcher, or unpins it.
+func (p *Projects) SetPinned(path string, pinned bool) (err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return ErrProjectNotFound
+	}
+
+	err = database.Service().UpsertProject(map[string]interface{}{"path": path, "pinned": pinned})
+	if err != nil {
+		return err
+	}
+
+	p.updateProject(path, func(project *dto.Project) {
+		project.Pinned = pinned
+	})
+
+	return nil
+}
+
+// LauncherProjects returns the projects for the quick launcher: pinned projects by path, then opened projects by
+// frecency. A query searches every visible project instead, keeping that order and listing projects which were never
+// opened last.
+func (p *Projects) LauncherProjects(query string) (_ []dto.Project, err error) {
+	defer failure.Wrap(&err)
+
+	history, err := p.history()
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := p.filterHidden(p.snapshot(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]dto.Project, 0, len(projects))
+
+	for _, project := range projects {
+		if query == "" && !project.Pinned && len(history[project.Path]) == 0 {
+			continue
+		}
+
+		if matchesQuery(project, query) {
+			listed = append(listed, project)
+		}
+	}
+
+	now := time.Now()
+
+	sort.SliceStable(listed, func(i, j int) bool {
+		a, b := listed[i], listed[j]
+
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+
+		if !a.Pinned {
+			scoreA, scoreB := frecency(history[a.Path], now), frecency(history[b.Path], now)
+			if scoreA != scoreB {
+				return scoreA > scoreB
+			}
+		}
+
+		return a.Path < b.Path
+	})
+
+	if len(listed) > launcherLimit {
+		listed = listed[:launcherLimit]
+	}
+
+	return listed, nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"github.com/go-git/go-git/v5"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/forge"
+	"github.com/mattouille/proman/vcs"
+)
+
+// repositoryLinks derives the forge links of the project at path from the first remote hosted on a known forge. The
+// compare link is made against the remote's default branch, falling back to the default branch reported by the forge.
+func (p *Projects) repositoryLinks(path string, repo *git.Repository, remotes []*git.Remote) *dto.Links {
+	forges := p.forges()
+
+	for _, remote := range remotes {
+		for _, url := range remote.Config().URLs {
+			r, ok := forge.Parse(url, forges)
+			if !ok {
+				continue
+			}
+
+			base := vcs.DefaultBranch(repo, remote.Config().Name)
+			if project, found := p.find(path); base == "" && found && project.Remote != nil {
+				base = project.Remote.DefaultBranch
+			}
+
+			links := r.Links(vcs.CurrentBranch(repo), base)
+
+			return &links
+		}
+	}
+
+	return &dto.Links{}
+}
+
+// FileURL returns the forge web page of a file in the project at path. file is relative to the project.
+func (p *Projects) FileURL(path, file string) (_ string, err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := p.find(path)
+	if !ok {
+		return "", ErrProjectNotFound
+	}
+
+	if project.Links == nil || project.Links.File == "" {
+		return "", ErrNoForge
+	}
+
+	return forge.FileURL(*project.Links, file), nil
+}
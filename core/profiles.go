@@ -0,0 +1,173 @@
+package core
+
+import (
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+)
+
+func NewProfiles(cfg *Config, projects *Projects, editors *EditorConfig) *Profiles {
+	return &Profiles{config: cfg, projects: projects, editors: editors}
+}
+
+// Profiles is the Profiles frontend service. Every profile has its own config and database, switching profiles
+// reloads the services depending on them without restarting.
+type Profiles struct {
+	runtime  *platform.Runtime
+	log      platform.Logger
+	bus      *events.Bus
+	config   *Config
+	projects *Projects
+	editors  *EditorConfig
+}
+
+func (p *Profiles) start(runtime *platform.Runtime) error {
+	p.runtime = runtime
+	p.log = p.runtime.Log("profiles")
+	p.bus = events.NewBus(p.runtime.Events, p.log)
+
+	return nil
+}
+
+// GetAll returns all profiles, the default profile first.
+func (p *Profiles) GetAll() (_ []dto.Profile, err error) {
+	defer failure.Wrap(&err)
+
+	names, err := config.Profiles()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := config.ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]dto.Profile, len(names))
+
+	for i, name := range names {
+		profiles[i] = dto.Profile{Name: name, Active: name == active}
+	}
+
+	return profiles, nil
+}
+
+// Create creates an empty profile. It is set up like a first run once switched to.
+func (p *Profiles) Create(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	err = config.CreateProfile(name)
+	if err != nil {
+		return err
+	}
+
+	p.bus.Emit(events.ProfilesChanged)
+
+	return nil
+}
+
+// Delete removes a profile with its config and data. The default and active profiles cannot be deleted.
+func (p *Profiles) Delete(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	err = config.DeleteProfile(name)
+	if err != nil {
+		return err
+	}
+
+	p.bus.Emit(events.ProfilesChanged)
+
+	return nil
+}
+
+// Switch makes name the active profile. Its database and config replace the current ones, after which projects and
+// editors are reloaded. The background scans of the current profile are stopped first so they don't write to the new
+// database. The current profile stays active when the new one can't be opened.
+func (p *Profiles) Switch(name string) (err error) {
+	defer failure.Wrap(&err)
+
+	exists, err := config.ProfileExists(name)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return config.ErrProfileNotFound
+	}
+
+	active, err := config.ActiveProfile()
+	if err != nil {
+		return err
+	}
+
+	if name == active {
+		return nil
+	}
+
+	p.log.DebugFields("Switching profile", platform.Fields{"from": active, "to": name})
+
+	file, err := config.ProfileConfigFile(name)
+	if err != nil {
+		return err
+	}
+
+	store, err := dataFile(name, database.FileName)
+	if err != nil {
+		return err
+	}
+
+	p.projects.stopScans()
+	defer p.projects.resumeScans()
+
+	err = database.Service().Switch(store)
+	if err != nil {
+		return err
+	}
+
+	err = config.ReadInConfig(file)
+	if err != nil {
+		p.restore(active)
+
+		return err
+	}
+
+	err = config.SetActiveProfile(name)
+	if err != nil {
+		p.restore(active)
+
+		return err
+	}
+
+	p.config.watch()
+	p.editors.reload()
+	p.projects.switched()
+
+	p.bus.Emit(events.ProfilesChanged)
+	p.bus.Emit(events.ProjectsChanged)
+
+	return nil
+}
+
+// restore switches back to the database and config of profile after switching away from it failed.
+func (p *Profiles) restore(profile string) {
+	store, err := dataFile(profile, database.FileName)
+	if err == nil {
+		err = database.Service().Switch(store)
+	}
+
+	if err != nil {
+		p.log.ErrorFields("Unable to restore profile database", platform.Fields{"profile": profile, "error": err})
+	}
+
+	file, err := config.ProfileConfigFile(profile)
+	if err == nil {
+		err = config.ReadInConfig(file)
+	}
+
+	if err != nil {
+		p.log.ErrorFields("Unable to restore profile config", platform.Fields{"profile": profile, "error": err})
+	}
+}
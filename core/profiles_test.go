@@ -0,0 +1,69 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/mattouille/proman/service/config"
+)
+
+func TestSwitchProfile(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "alpha")
+
+	work := filepath.Join(filepath.Dir(dir), "work")
+	mkdirs(t, work, "beta")
+
+	app := startApp(t, dir, "")
+
+	err := app.Projects.SetTags("alpha", []interface{}{"home"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = app.Profiles.Create("work")
+	if err != nil {
+		t.Fatalf("unable to create profile: %s", err)
+	}
+
+	file, err := config.ProfileConfigFile("work")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = os.WriteFile(file, []byte("project_directory = "+strconv.Quote(work)+"\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write profile config: %s", err)
+	}
+
+	err = app.Profiles.Switch("work")
+	if err != nil {
+		t.Fatalf("unable to switch profile: %s", err)
+	}
+
+	if active, _ := config.ActiveProfile(); active != "work" {
+		t.Errorf("active profile %s, want work", active)
+	}
+
+	projects := listProjects(t, app, false, nil)
+	if got, want := projectPaths(projects), []string{"beta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v in the work profile, want %v", got, want)
+	}
+
+	err = app.Profiles.Switch(config.DefaultProfile)
+	if err != nil {
+		t.Fatalf("unable to switch back: %s", err)
+	}
+
+	projects = listProjects(t, app, false, nil)
+	if got, want := projectPaths(projects), []string{"alpha"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("listed %v in the default profile, want %v", got, want)
+	}
+
+	if tags := projects[0].Tags; !reflect.DeepEqual(tags, []string{"home"}) {
+		t.Errorf("alpha tags %v, want the default profile's [home]", tags)
+	}
+}
@@ -0,0 +1,549 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/mattouille/proman/detect"
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/forge"
+	"github.com/mattouille/proman/path"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/vcs"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func NewProjects() *Projects {
+	return new(Projects)
+}
+
+// Projects is the Projects frontend service.
+type Projects struct {
+	runtime  *platform.Runtime
+	log      platform.Logger
+	bus      *events.Bus
+	projects []dto.Project
+	// root is the absolute path of the project directory the projects were loaded from
+	root string
+	// mu guards projects and root against updates from background work
+	mu sync.RWMutex
+	// scanning is set while a background disk usage scan is running
+	scanning bool
+	// enriching is set while remote metadata is being fetched in the background
+	enriching bool
+	// background tracks the scans started by reload
+	background sync.WaitGroup
+	// scans is cancelled to stop the background scans, see stopScans
+	scans       context.Context
+	cancelScans context.CancelFunc
+	// quit is closed to stop scanning disk usage periodically, scheduler tracks the goroutine doing so
+	quit      chan struct{}
+	scheduler sync.WaitGroup
+	// degraded is the reason projects couldn't be loaded, nil when they were
+	degraded error
+}
+
+func (p *Projects) start(runtime *platform.Runtime) error {
+	p.runtime = runtime
+	p.log = p.runtime.Log("project")
+	p.bus = events.NewBus(p.runtime.Events, p.log)
+
+	p.registerEvents()
+
+	p.quit = make(chan struct{})
+	p.scheduler.Add(1)
+
+	go p.scanPeriodically(p.quit, diskScanInterval)
+
+	// a missing or invalid project directory shouldn't stop the app, the frontend walks the user through setup instead
+	err := p.reload()
+	if err != nil {
+		p.log.ErrorFields("Unable to load projects, starting in degraded mode", platform.Fields{"error": err})
+
+		return nil
+	}
+
+	_, err = p.PruneHistory()
+	if err != nil {
+		p.log.ErrorFields("Unable to prune project history", platform.Fields{"error": err})
+	}
+
+	return nil
+}
+
+// stop ends the periodic and the running background scans.
+func (p *Projects) stop() {
+	if p.quit != nil {
+		close(p.quit)
+		p.scheduler.Wait()
+
+		p.quit = nil
+	}
+
+	p.stopScans()
+}
+
+// reload loads the projects from the configured project directory and starts the background scans. When loading fails
+// the previously loaded projects are kept and the error is recorded as the reason for running degraded.
+func (p *Projects) reload() error {
+	err := p.load()
+
+	p.mu.Lock()
+	p.degraded = err
+	p.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	p.resumeScans()
+	p.startScans(p.scanDiskUsage, p.enrichRemotes)
+
+	return nil
+}
+
+// startScans runs scans in the background, unless the scans were stopped. They are passed a context which is
+// cancelled when they should give up early.
+func (p *Projects) startScans(scans ...func(ctx context.Context)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.scans == nil || p.scans.Err() != nil {
+		return
+	}
+
+	for _, scan := range scans {
+		p.background.Add(1)
+
+		go func(ctx context.Context, scan func(ctx context.Context)) {
+			defer p.background.Done()
+
+			scan(ctx)
+		}(p.scans, scan)
+	}
+}
+
+// stopScans cancels the background scans and waits for them to return. No scans are started until resumeScans, so
+// the database they write to can be switched or closed safely.
+func (p *Projects) stopScans() {
+	p.mu.Lock()
+	if p.cancelScans != nil {
+		p.cancelScans()
+	}
+	p.mu.Unlock()
+
+	p.background.Wait()
+}
+
+// resumeScans allows background scans to be started again after stopScans.
+func (p *Projects) resumeScans() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.scans == nil || p.scans.Err() != nil {
+		p.scans, p.cancelScans = context.WithCancel(context.Background())
+	}
+}
+
+func (p *Projects) load() error {
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return err
+	}
+
+	paths, err := p.loadProjectsFromDisk(cfg.ProjectDirectory)
+	if err != nil {
+		return err
+	}
+
+	paths = append(paths, p.loadSubProjects(paths)...)
+
+	projects, err := p.syncProjectMetadata(paths)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.projects = projects
+	p.mu.Unlock()
+
+	return nil
+}
+
+// rescan reloads the projects after something changed on disk and tells the frontend to refetch them.
+func (p *Projects) rescan() error {
+	err := p.reload()
+	if err != nil {
+		return err
+	}
+
+	p.bus.Emit(events.ProjectsChanged)
+
+	return nil
+}
+
+// configChanged applies an edited config. Projects are rescanned when the project directory changed, the frontend is
+// told to refetch them when the change affects which projects are listed.
+func (p *Projects) configChanged(previous, current dto.ConfigSchema) {
+	if current.ProjectDirectory != previous.ProjectDirectory {
+		p.log.DebugFields("Project directory changed, rescanning", platform.Fields{"path": current.ProjectDirectory})
+
+		err := p.reload()
+		if err != nil {
+			p.log.ErrorFields("Unable to load projects from new project directory", platform.Fields{"error": err})
+		}
+	} else if reflect.DeepEqual(current.HiddenPatterns, previous.HiddenPatterns) {
+		return
+	}
+
+	p.bus.Emit(events.ProjectsChanged)
+}
+
+// switched drops the projects of the previous profile and loads those of the new one.
+func (p *Projects) switched() {
+	p.mu.Lock()
+	p.projects = nil
+	p.root = ""
+	p.mu.Unlock()
+
+	err := p.reload()
+	if err != nil {
+		p.log.ErrorFields("Unable to load projects of profile, starting in degraded mode", platform.Fields{"error": err})
+	}
+}
+
+// loadError returns why projects couldn't be loaded, or nil when they were.
+func (p *Projects) loadError() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.degraded
+}
+
+// Registers events which can be called via the wails runtime
+func (p *Projects) registerEvents() {
+	p.bus.Handle(events.OpenURL, func(payload interface{}) error {
+		url := payload.(string)
+
+		p.log.DebugFields("Opening URL", platform.Fields{"url": url})
+
+		return p.runtime.Browser.OpenURL(url)
+	})
+
+	p.bus.Handle(events.OpenProject, func(payload interface{}) error {
+		return p.OpenProject(payload.(string))
+	})
+
+	p.bus.On(events.ConfigChanged, func(args ...interface{}) {
+		p.configChanged(args[1].(dto.ConfigSchema), args[0].(dto.ConfigSchema))
+	})
+
+	// the result only tells whether the batch was accepted, the per-project results follow with
+	// projects.batch.completed once every project is done
+	p.bus.Handle(events.ProjectsBatch, func(payload interface{}) error {
+		b, err := p.planBatch(payload.(dto.BatchRequest))
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			p.bus.Emit(events.ProjectsBatchCompleted, p.execute(b))
+		}()
+
+		return nil
+	})
+}
+
+// Returns a slice of paths known to be project directories in the project directory path
+func (p *Projects) loadProjectsFromDisk(projectDir string) ([]string, error) {
+	var projects []string
+
+	abs, err := path.ExpandAndValidate(projectDir)
+	if err != nil {
+		p.log.ErrorFields("Failed loading project directory", platform.Fields{"path": projectDir, "error": err})
+
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.root = abs
+	p.mu.Unlock()
+
+	files, err := ioutil.ReadDir(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	// create or update project
+	for _, f := range files {
+		if f.IsDir() {
+			projects = append(projects, f.Name())
+
+			p.log.DebugFields("Searching repository git path", platform.Fields{"path": p.absPath(f.Name())})
+
+			// todo: in the future, more vcs providers could be supported.
+			repo, err := vcs.Open(p.absPath(f.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			if repo == nil {
+				p.log.Debug("Git repository not detected")
+			}
+
+			var (
+				remotes    []string
+				urls       []string
+				links      = &dto.Links{}
+				branch     string
+				worktreeOf string
+			)
+
+			if repo != nil {
+				p.log.Debug("Git repository detected")
+
+				rmts, err := repo.Remotes()
+				if err != nil {
+					return nil, err
+				}
+
+				for _, remote := range rmts {
+					remotes = append(remotes, remote.Config().URLs...)
+					urls = append(urls, p.ParseRepositoryURL(remote.Config().URLs)...)
+				}
+
+				links = p.repositoryLinks(f.Name(), repo, rmts)
+				branch = vcs.CurrentBranch(repo)
+				worktreeOf = p.worktreeOf(f.Name())
+			}
+
+			languages := detect.Languages(p.absPath(f.Name()))
+
+			p.log.DebugFields("Found project",
+				platform.Fields{"name": f.Name(), "remotes": remotes, "urls": urls, "languages": languages})
+
+			err = database.Service().UpsertProject(map[string]interface{}{
+				"path":            f.Name(),
+				"remotes":         remotes,
+				"repository_urls": urls,
+				"languages":       languages,
+				"auto_tags":       detect.Tags(languages),
+				"links":           links,
+				"branch":          branch,
+				"worktree_of":     worktreeOf,
+			})
+			if err != nil {
+				p.log.ErrorFields("Error while upserting project", platform.Fields{"error": err})
+			}
+		}
+	}
+
+	return projects, nil
+}
+
+// absPath returns the absolute path of a project path relative to the project directory.
+func (p *Projects) absPath(projectPath string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return filepath.Join(p.root, projectPath)
+}
+
+// Retrieves a list of all active projects from the database, matches them by path, and sets any defaults on new metadata.
+// paths should be relative to the project directory.
+func (p *Projects) syncProjectMetadata(paths []string) ([]dto.Project, error) {
+	projects, err := database.Service().GetAllProjects()
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return nil, err
+	}
+
+	listed := make(map[string]bool, len(paths))
+	for _, projectPath := range paths {
+		listed[projectPath] = true
+	}
+
+	stored := make(map[string]dto.Project, len(projects))
+
+	// delete projects which no longer exist
+	for _, project := range projects {
+		if listed[project.Path] {
+			stored[project.Path] = project
+
+			continue
+		}
+
+		err := database.Service().DeleteProject(project.Path)
+		if err != nil {
+			p.log.ErrorFields("Unable to delete project", platform.Fields{"path": project.Path})
+		}
+	}
+
+	var final []dto.Project
+
+	// keep the order of the path list
+	for _, projectPath := range paths {
+		if project, ok := stored[projectPath]; ok {
+			final = append(final, project)
+		}
+	}
+
+	return final, nil
+}
+
+// GetAll fetches all projects from the database. Hidden projects are left out unless options sets "include_hidden".
+// options may also set "sort" to one of name, frecency or recent.
+func (p *Projects) GetAll(refresh bool, options map[string]interface{}) (_ []dto.Project, err error) {
+	defer failure.Wrap(&err)
+
+	var opts dto.ListOptions
+
+	err = mapstructure.Decode(options, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode list options: %w", err)
+	}
+
+	if refresh {
+		err = p.reload()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	projects, err := p.filterHidden(p.snapshot(), opts.IncludeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.sortProjects(projects, opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// OpenProject opens the project at path with the editor resolved from its OpenWith setting, the editor rules or the
+// default editor, in that order.
+func (p *Projects) OpenProject(path string) (err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := p.find(path)
+	if !ok {
+		return ErrProjectNotFound
+	}
+
+	editors, err := database.Service().GetEditors()
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return err
+	}
+
+	rules, err := database.Service().GetEditorRules()
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return err
+	}
+
+	abs := p.absPath(path)
+
+	editor, err := resolveEditor(project, abs, rules, editors)
+	if err != nil {
+		return err
+	}
+
+	p.log.DebugFields("Opening project", platform.Fields{"path": path, "editor": editor.Name})
+
+	cmd := exec.Command(editor.Path, abs)
+	cmd.Dir = abs
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	// reap the editor process once it exits so it doesn't linger as a zombie
+	go func() { _ = cmd.Wait() }()
+
+	p.recordOpen(path, editor)
+
+	return nil
+}
+
+// SetTags replaces the tags of the project at path. tags is a list of strings as sent by the frontend.
+func (p *Projects) SetTags(path string, data []interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	var tags []string
+
+	err = mapstructure.Decode(data, &tags)
+	if err != nil {
+		return fmt.Errorf("unable to decode tags: %w", err)
+	}
+
+	err = database.Service().UpsertProject(map[string]interface{}{"path": path, "tags": tags})
+	if err != nil {
+		return err
+	}
+
+	p.updateProject(path, func(project *dto.Project) {
+		project.Tags = tags
+	})
+
+	return nil
+}
+
+// updateProject applies fn to the loaded project at path, if there is one.
+func (p *Projects) updateProject(path string, fn func(project *dto.Project)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range p.projects {
+		if p.projects[i].Path == path {
+			fn(&p.projects[i])
+		}
+	}
+}
+
+// snapshot returns a copy of the loaded projects which is safe to use from background work.
+func (p *Projects) snapshot() []dto.Project {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return append([]dto.Project(nil), p.projects...)
+}
+
+// ParseRepositoryURL parses remote urls and returns the expected repository urls. If one cannot be determined it returns
+// nil.
+//
+// Remotes can be in the form of http(s) or ssh, and must be hosted on one of the built in or configured forges.
+func (p *Projects) ParseRepositoryURL(urls []string) []string {
+	var repos []string
+
+	forges := p.forges()
+
+	for _, url := range urls {
+		p.log.DebugFields("Parsing URL", platform.Fields{"url": url})
+
+		repo, ok := forge.Parse(url, forges)
+		if !ok {
+			p.log.DebugFields("Failed to parse URL", platform.Fields{"url": url})
+
+			continue
+		}
+
+		p.log.DebugFields("Matched URL", platform.Fields{"url": url, "forge": repo.Forge.Kind})
+
+		repos = append(repos, repo.HomeURL())
+	}
+
+	return repos
+}
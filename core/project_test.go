@@ -0,0 +1,286 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// projectPaths returns the paths of projects.
+func projectPaths(projects []dto.Project) []string {
+	paths := []string{}
+
+	for _, project := range projects {
+		paths = append(paths, project.Path)
+	}
+
+	return paths
+}
+
+// listProjects lists the projects of app with options, failing the test on errors.
+func listProjects(t *testing.T, app *testApp, refresh bool, options map[string]interface{}) []dto.Project {
+	t.Helper()
+
+	projects, err := app.Projects.GetAll(refresh, options)
+	if err != nil {
+		t.Fatalf("unable to list projects: %s", err)
+	}
+
+	return projects
+}
+
+// findProject returns the project at path from projects, failing the test when there is none.
+func findProject(t *testing.T, projects []dto.Project, path string) dto.Project {
+	t.Helper()
+
+	for _, project := range projects {
+		if project.Path == path {
+			return project
+		}
+	}
+
+	t.Fatalf("project %s not listed in %v", path, projectPaths(projects))
+
+	return dto.Project{}
+}
+
+func TestGetAll(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "beta", "alpha", "archive-old")
+
+	err := os.WriteFile(filepath.Join(dir, "alpha", "go.mod"), []byte("module alpha\n\ngo 1.16\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write go.mod: %s", err)
+	}
+
+	app := startApp(t, dir, `hidden_patterns = ["archive-*"]`+"\n")
+
+	byName := map[string]interface{}{"sort": "name"}
+
+	projects := listProjects(t, app, false, byName)
+	if got, want := projectPaths(projects), []string{"alpha", "beta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v, want %v", got, want)
+	}
+
+	if tags := findProject(t, projects, "alpha").AutoTags; len(tags) == 0 || tags[0] != "go" {
+		t.Errorf("alpha auto tags %v, want go", tags)
+	}
+
+	all := listProjects(t, app, false, map[string]interface{}{"sort": "name", "include_hidden": true})
+	if got, want := projectPaths(all), []string{"alpha", "archive-old", "beta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v with hidden projects, want %v", got, want)
+	}
+
+	if !findProject(t, all, "archive-old").Hidden {
+		t.Errorf("archive-old matches a hidden pattern but is not hidden")
+	}
+
+	_, err = app.Projects.GetAll(false, map[string]interface{}{"sort": "size"})
+	if err == nil {
+		t.Errorf("listed projects with an unknown sort option")
+	}
+}
+
+func TestProjectMetadata(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "alpha", "beta")
+
+	app := startApp(t, dir, "")
+
+	err := app.Projects.SetTags("alpha", []interface{}{"work", "go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = app.Projects.SetHidden("beta", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = app.Projects.SetPinned("alpha", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the metadata survives a rescan which finds a new project
+	mkdirs(t, dir, "gamma")
+
+	projects := listProjects(t, app, true, map[string]interface{}{"sort": "name", "include_hidden": true})
+	if got, want := projectPaths(projects), []string{"alpha", "beta", "gamma"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("listed %v, want %v", got, want)
+	}
+
+	alpha := findProject(t, projects, "alpha")
+	if !reflect.DeepEqual(alpha.Tags, []string{"work", "go"}) || !alpha.Pinned {
+		t.Errorf("alpha has tags %v and pinned %t, want its metadata kept", alpha.Tags, alpha.Pinned)
+	}
+
+	if beta := findProject(t, projects, "beta"); !beta.Hide || !beta.Hidden {
+		t.Errorf("beta is not hidden anymore")
+	}
+
+	visible := listProjects(t, app, false, map[string]interface{}{"sort": "name"})
+	if got, want := projectPaths(visible), []string{"alpha", "gamma"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v, want %v", got, want)
+	}
+
+	// removed directories are not listed anymore
+	err = os.Remove(filepath.Join(dir, "gamma"))
+	if err != nil {
+		t.Fatalf("unable to remove gamma: %s", err)
+	}
+
+	visible = listProjects(t, app, true, map[string]interface{}{"sort": "name"})
+	if got, want := projectPaths(visible), []string{"alpha"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v after removing gamma, want %v", got, want)
+	}
+}
+
+func TestSetTagsRemovesTags(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "alpha")
+
+	app := startApp(t, dir, "")
+
+	for _, tags := range [][]interface{}{{"work", "go", "api"}, {"go"}} {
+		err := app.Projects.SetTags("alpha", tags)
+		if err != nil {
+			t.Fatalf("unable to set tags %v: %s", tags, err)
+		}
+	}
+
+	projects := listProjects(t, app, true, nil)
+	if tags := findProject(t, projects, "alpha").Tags; !reflect.DeepEqual(tags, []string{"go"}) {
+		t.Errorf("tags %v after reloading, want [go]", tags)
+	}
+
+	err := app.Projects.SetTags("alpha", []interface{}{})
+	if err != nil {
+		t.Fatalf("unable to clear tags: %s", err)
+	}
+
+	projects = listProjects(t, app, true, nil)
+	if tags := findProject(t, projects, "alpha").Tags; len(tags) != 0 {
+		t.Errorf("tags %v after clearing and reloading, want none", tags)
+	}
+}
+
+func TestUnknownProject(t *testing.T) {
+	dir := newProjectDir(t)
+	app := startApp(t, dir, "")
+
+	tests := map[string]func() error{
+		"open":    func() error { return app.Projects.OpenProject("missing") },
+		"hide":    func() error { return app.Projects.SetHidden("missing", true) },
+		"pin":     func() error { return app.Projects.SetPinned("missing", true) },
+		"editor":  func() error { return app.Projects.SetOpenWith("missing", "vim") },
+		"add sub": func() error { return app.Projects.AddSubProject("missing", "api") },
+	}
+
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := fn(); !errors.Is(err, ErrProjectNotFound) {
+				t.Errorf("error %v, want %v", err, ErrProjectNotFound)
+			}
+		})
+	}
+}
+
+func TestSubProjects(t *testing.T) {
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "mono/services/api", "mono/web", "other")
+
+	app := startApp(t, dir, "")
+
+	err := app.Projects.AddSubProject("mono", "services/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	projects := listProjects(t, app, false, map[string]interface{}{"sort": "name"})
+	if got, want := projectPaths(projects), []string{"mono", "mono/services/api", "other"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("listed %v, want %v", got, want)
+	}
+
+	if parent := findProject(t, projects, "mono/services/api").Parent; parent != "mono" {
+		t.Errorf("parent %q, want mono", parent)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		dir  string
+		err  error
+	}{
+		{name: "outside", path: "mono", dir: "../other", err: ErrSubProjectDir},
+		{name: "absolute", path: "mono", dir: filepath.Join(dir, "other"), err: ErrSubProjectDir},
+		{name: "project itself", path: "mono", dir: ".", err: ErrSubProjectDir},
+		{name: "missing", path: "mono", dir: "services/db", err: ErrSubProjectDir},
+		{name: "nested", path: "mono/services/api", dir: "cmd", err: ErrNestedSubProject},
+		{name: "existing", path: "mono", dir: "services/api", err: ErrProjectExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.Projects.AddSubProject(tt.path, tt.dir); !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	err = app.Projects.RemoveSubProject("mono")
+	if !errors.Is(err, ErrNotSubProject) {
+		t.Errorf("error %v removing a project, want %v", err, ErrNotSubProject)
+	}
+
+	err = app.Projects.RemoveSubProject("mono/services/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	projects = listProjects(t, app, false, map[string]interface{}{"sort": "name"})
+	if got, want := projectPaths(projects), []string{"mono", "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v after removing the sub-project, want %v", got, want)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "mono", "services", "api")); err != nil {
+		t.Errorf("the sub-project directory was touched: %s", err)
+	}
+}
+
+func TestOpenProject(t *testing.T) {
+	bin, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("the true command is required to stand in for an editor")
+	}
+
+	dir := newProjectDir(t)
+	mkdirs(t, dir, "alpha", "beta")
+
+	app := startApp(t, dir, "")
+
+	err = app.Projects.OpenProject("beta")
+	if !errors.Is(err, ErrNoEditor) {
+		t.Errorf("error %v without editors, want %v", err, ErrNoEditor)
+	}
+
+	err = app.EditorConfig.UpsertEditor(map[string]interface{}{"name": "true", "path": bin, "default": true})
+	if err != nil {
+		t.Fatalf("unable to add editor: %s", err)
+	}
+
+	err = app.Projects.OpenProject("beta")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	recent := listProjects(t, app, false, map[string]interface{}{"sort": "recent"})
+	if got, want := projectPaths(recent), []string{"beta", "alpha"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listed %v by recency, want %v", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"errors"
+	"os"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/path"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/secrets"
+)
+
+var ErrNoSecretStore = errors.New("secret store is not available")
+
+// PassphraseEnv unlocks the file secret store at startup when set.
+const PassphraseEnv = "PROMAN_SECRETS_PASSPHRASE"
+
+// openSecrets opens the configured secret store, unlocking it from the environment if a passphrase is set.
+func openSecrets() error {
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return err
+	}
+
+	// credentials are shared by all profiles
+	file, err := dataFile(config.DefaultProfile, secrets.FileName)
+	if err != nil {
+		return err
+	}
+
+	err = secrets.New(cfg.SecretsBackend, file)
+	if err != nil {
+		return err
+	}
+
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return nil
+	}
+
+	return secrets.Service().Unlock(passphrase)
+}
+
+func NewSecrets() *Secrets {
+	return new(Secrets)
+}
+
+// Secrets is the Secrets frontend service. Secret values are write only, the frontend can only learn which keys exist.
+type Secrets struct {
+	runtime *platform.Runtime
+	log     platform.Logger
+	bus     *events.Bus
+}
+
+func (s *Secrets) start(runtime *platform.Runtime) error {
+	s.runtime = runtime
+	s.log = s.runtime.Log("secrets")
+	s.bus = events.NewBus(s.runtime.Events, s.log)
+
+	s.bus.On(events.ConfigChanged, func(args ...interface{}) {
+		current, previous := args[0].(dto.ConfigSchema), args[1].(dto.ConfigSchema)
+		if current.SecretsBackend == previous.SecretsBackend {
+			return
+		}
+
+		err := openSecrets()
+		if err != nil {
+			s.log.ErrorFields("Unable to open secret store", platform.Fields{"backend": current.SecretsBackend, "error": err})
+
+			return
+		}
+
+		s.bus.Emit(events.SecretsChanged)
+	})
+
+	return nil
+}
+
+// Unlock unlocks the secret store with passphrase. The first unlock of a new file store sets its passphrase.
+func (s *Secrets) Unlock(passphrase string) (err error) {
+	defer failure.Wrap(&err)
+
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+
+	err = store.Unlock(passphrase)
+	if err != nil {
+		return err
+	}
+
+	s.bus.Emit(events.SecretsChanged)
+
+	return nil
+}
+
+// Locked reports whether the secret store must be unlocked before use.
+func (s *Secrets) Locked() bool {
+	store, err := s.store()
+
+	return err != nil || store.Locked()
+}
+
+// List returns the keys of all stored secrets.
+func (s *Secrets) List() (_ []string, err error) {
+	defer failure.Wrap(&err)
+
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.List()
+}
+
+// Has reports whether a secret is stored under key.
+func (s *Secrets) Has(key string) bool {
+	_, ok := secrets.Lookup(key)
+
+	return ok
+}
+
+// Set stores value under key.
+func (s *Secrets) Set(key, value string) (err error) {
+	defer failure.Wrap(&err)
+
+	s.log.DebugFields("Storing secret", platform.Fields{"key": key})
+
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+
+	return s.changed(store.Set(key, value))
+}
+
+// SetForgeToken stores the API token for the forge at host.
+func (s *Secrets) SetForgeToken(host, token string) (err error) {
+	defer failure.Wrap(&err)
+
+	return s.Set(secrets.ForgeTokenKey(host), token)
+}
+
+// SetGitCredential stores the credentials used for http(s) remotes on host. username may be blank for token auth.
+func (s *Secrets) SetGitCredential(host, username, password string) (err error) {
+	defer failure.Wrap(&err)
+
+	if username != "" {
+		password = username + ":" + password
+	}
+
+	return s.Set(secrets.GitCredentialKey(host), password)
+}
+
+// SetSSHPassphrase stores the passphrase of the ssh private key at keyPath.
+func (s *Secrets) SetSSHPassphrase(keyPath, passphrase string) (err error) {
+	defer failure.Wrap(&err)
+
+	abs, err := path.Expand(keyPath)
+	if err != nil {
+		return err
+	}
+
+	return s.Set(secrets.SSHPassphraseKey(abs), passphrase)
+}
+
+// Delete removes the secret stored under key.
+func (s *Secrets) Delete(key string) (err error) {
+	defer failure.Wrap(&err)
+
+	s.log.DebugFields("Deleting secret", platform.Fields{"key": key})
+
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+
+	return s.changed(store.Delete(key))
+}
+
+// store returns the secret store, which is missing when it failed to open at startup.
+func (s *Secrets) store() (secrets.Store, error) {
+	store := secrets.Service()
+	if store == nil {
+		return nil, ErrNoSecretStore
+	}
+
+	return store, nil
+}
+
+// changed notifies the frontend that the stored secrets changed when err is nil, and passes err through.
+func (s *Secrets) changed(err error) error {
+	if err != nil {
+		return err
+	}
+
+	s.bus.Emit(events.SecretsChanged)
+
+	return nil
+}
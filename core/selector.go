@@ -0,0 +1,96 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/path"
+)
+
+// Select returns the known projects matched by the selector.
+func (p *Projects) Select(selector dto.ProjectSelector) (_ []dto.Project, err error) {
+	defer failure.Wrap(&err)
+
+	var root string
+
+	if selector.Root != "" {
+		abs, err := path.ExpandAndValidate(selector.Root)
+		if err != nil {
+			return nil, err
+		}
+
+		root = abs
+	}
+
+	var selected []dto.Project
+
+	for _, project := range p.snapshot() {
+		if root != "" && !isWithin(root, p.absPath(project.Path)) {
+			continue
+		}
+
+		if !hasTags(project, selector.Tags) {
+			continue
+		}
+
+		if !matchesQuery(project, selector.Query) {
+			continue
+		}
+
+		selected = append(selected, project)
+	}
+
+	return selected, nil
+}
+
+// isWithin reports whether target is root or a descendant of root.
+func isWithin(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// hasTags reports whether the project carries every tag in tags, counting automatic tags.
+func hasTags(project dto.Project, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+
+		for _, t := range project.AllTags() {
+			if strings.EqualFold(t, tag) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesQuery reports whether the project path, tags or remotes contain the query, ignoring case.
+func matchesQuery(project dto.Project, query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+
+	fields := append([]string{project.Path}, project.AllTags()...)
+	fields = append(fields, project.Remotes...)
+
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+)
+
+func TestMatchesQuery(t *testing.T) {
+	project := dto.Project{
+		Path:     "proman",
+		Tags:     []string{"Work"},
+		AutoTags: []string{"go"},
+		Remotes:  []string{"git@github.com:mattouille/proman.git"},
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: true},
+		{query: "  ", want: true},
+		{query: "PRO", want: true},
+		{query: "work", want: true},
+		{query: "go", want: true},
+		{query: "mattouille", want: true},
+		{query: "github.com", want: true},
+		{query: "rust", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := matchesQuery(project, tt.query); got != tt.want {
+				t.Errorf("matchesQuery(%q) = %t, want %t", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattouille/proman/detect"
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/path"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+var ErrSetupIncomplete = errors.New("a project directory must be set before setup can be finished")
+
+// candidateDirectories commonly hold projects and are suggested during setup when they exist.
+var candidateDirectories = []string{"~/src", "~/code", "~/projects", "~/go/src"}
+
+func NewSetup(cfg *Config, projects *Projects) *Setup {
+	return &Setup{config: cfg, projects: projects}
+}
+
+// Setup is the Setup frontend service. It guides the user through first run setup: choosing a project directory, then
+// the editors projects are opened with.
+type Setup struct {
+	runtime  *platform.Runtime
+	log      platform.Logger
+	bus      *events.Bus
+	config   *Config
+	projects *Projects
+}
+
+func (s *Setup) start(runtime *platform.Runtime) error {
+	s.runtime = runtime
+	s.log = s.runtime.Log("setup")
+	s.bus = events.NewBus(s.runtime.Events, s.log)
+
+	return nil
+}
+
+// State reports the next setup step along with suggested project directories and detected editors.
+func (s *Setup) State() (_ dto.SetupState, err error) {
+	defer failure.Wrap(&err)
+
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		return dto.SetupState{}, err
+	}
+
+	state := dto.SetupState{
+		Step:       dto.SetupComplete,
+		Candidates: candidates(),
+		Editors:    detect.Editors(),
+	}
+
+	if err := s.projects.loadError(); err != nil {
+		state.Degraded = true
+		state.Reason = err.Error()
+	}
+
+	editors, err := database.Service().GetEditors()
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return dto.SetupState{}, err
+	}
+
+	_, err = path.ExpandAndValidate(cfg.ProjectDirectory)
+
+	switch {
+	case err != nil:
+		state.Step = dto.SetupProjectDirectory
+	case !cfg.SetupComplete && len(editors) == 0:
+		// existing installs with editors configured predate setup and count as complete
+		state.Step = dto.SetupEditors
+	}
+
+	state.Complete = state.Step == dto.SetupComplete
+
+	return state, nil
+}
+
+// SetProjectDirectory validates and stores the project directory, then loads the projects in it.
+func (s *Setup) SetProjectDirectory(dir string) (_ dto.SetupState, err error) {
+	defer failure.Wrap(&err)
+
+	err = s.config.Update(map[string]interface{}{"project_directory": dir})
+	if err != nil {
+		return dto.SetupState{}, err
+	}
+
+	err = s.projects.reload()
+	if err != nil {
+		return dto.SetupState{}, err
+	}
+
+	return s.changed()
+}
+
+// AddEditors stores the editors chosen from the detected ones. data is a list of editors as sent by the frontend. The
+// first editor becomes the default when there is none yet.
+func (s *Setup) AddEditors(data []interface{}) (_ dto.SetupState, err error) {
+	defer failure.Wrap(&err)
+
+	var editors []dto.Editor
+
+	err = mapstructure.Decode(data, &editors)
+	if err != nil {
+		return dto.SetupState{}, fmt.Errorf("unable to decode editors: %w", err)
+	}
+
+	existing, err := database.Service().GetEditors()
+	if err != nil && !errors.Is(err, database.ErrNoRecords) {
+		return dto.SetupState{}, err
+	}
+
+	hasDefault := false
+
+	for _, editor := range existing {
+		hasDefault = hasDefault || editor.Default
+	}
+
+	for i, editor := range editors {
+		err := database.Service().UpsertEditor(map[string]interface{}{
+			"name":    editor.Name,
+			"path":    editor.Path,
+			"default": !hasDefault && i == 0,
+		})
+		if err != nil {
+			return dto.SetupState{}, err
+		}
+	}
+
+	return s.changed()
+}
+
+// Finish marks setup as complete. Adding editors is optional, so it may be skipped.
+func (s *Setup) Finish() (_ dto.SetupState, err error) {
+	defer failure.Wrap(&err)
+
+	state, err := s.State()
+	if err != nil {
+		return dto.SetupState{}, err
+	}
+
+	if state.Step == dto.SetupProjectDirectory {
+		return state, ErrSetupIncomplete
+	}
+
+	err = s.config.Update(map[string]interface{}{"setup_complete": true})
+	if err != nil {
+		return dto.SetupState{}, err
+	}
+
+	return s.changed()
+}
+
+// changed notifies the frontend of the new setup state and returns it.
+func (s *Setup) changed() (dto.SetupState, error) {
+	state, err := s.State()
+	if err != nil {
+		return dto.SetupState{}, err
+	}
+
+	s.log.DebugFields("Setup state changed", platform.Fields{"step": state.Step})
+
+	s.bus.Emit(events.SetupChanged, state)
+
+	return state, nil
+}
+
+// candidates returns the candidate project directories which exist.
+func candidates() []string {
+	dirs := []string{}
+
+	for _, dir := range candidateDirectories {
+		abs, err := path.ExpandAndValidate(dir)
+		if err != nil {
+			continue
+		}
+
+		dirs = append(dirs, abs)
+	}
+
+	return dirs
+}
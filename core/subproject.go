@@ -0,0 +1,187 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattouille/proman/detect"
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/service/secrets"
+	"github.com/mattouille/proman/vcs"
+)
+
+var (
+	ErrSubProjectDir    = errors.New("a sub-project must be a directory inside its project")
+	ErrNestedSubProject = errors.New("sub-projects can't have sub-projects")
+	ErrNotSubProject    = errors.New("project is not a sub-project")
+	ErrProjectExists    = errors.New("project already exists")
+)
+
+// AddSubProject declares the directory dir, relative to the project at path, a sub-project. It is listed as a project
+// of its own with its own editor, actions and tags, for example a service in a monorepo.
+func (p *Projects) AddSubProject(path, dir string) (err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := p.find(path)
+	if !ok {
+		return ErrProjectNotFound
+	}
+
+	if project.Parent != "" {
+		return ErrNestedSubProject
+	}
+
+	dir = filepath.Clean(dir)
+	if dir == "." || filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", ErrSubProjectDir, dir)
+	}
+
+	sub := filepath.ToSlash(filepath.Join(path, dir))
+
+	info, err := os.Stat(p.absPath(sub))
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("%w: %s", ErrSubProjectDir, dir)
+	}
+
+	if _, ok := p.find(sub); ok {
+		return fmt.Errorf("%w: %s", ErrProjectExists, sub)
+	}
+
+	p.log.DebugFields("Adding sub-project", platform.Fields{"path": path, "dir": dir})
+
+	err = database.Service().UpsertProject(map[string]interface{}{"path": sub, "parent": path})
+	if err != nil {
+		return err
+	}
+
+	return p.rescan()
+}
+
+// RemoveSubProject stops listing the sub-project at path. The directory is left untouched.
+func (p *Projects) RemoveSubProject(path string) (err error) {
+	defer failure.Wrap(&err)
+
+	project, ok := p.find(path)
+	if !ok {
+		return ErrProjectNotFound
+	}
+
+	if project.Parent == "" {
+		return ErrNotSubProject
+	}
+
+	err = database.Service().DeleteProject(path)
+	if err != nil {
+		return err
+	}
+
+	return p.rescan()
+}
+
+// SetOpenWith sets the editor the project at path is opened with, by editor name or binary. A blank editor goes back
+// to the editor rules and the default editor.
+func (p *Projects) SetOpenWith(path, editor string) (err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return ErrProjectNotFound
+	}
+
+	err = database.Service().UpsertProject(map[string]interface{}{"path": path, "open_with": editor})
+	if err != nil {
+		return err
+	}
+
+	p.updateProject(path, func(project *dto.Project) {
+		project.OpenWith = editor
+	})
+
+	return nil
+}
+
+// Submodules lists the git submodules of the project at path. With remote set the head of every submodule's remote
+// is looked up too, to tell which pinned commits are behind.
+func (p *Projects) Submodules(path string, remote bool) (_ []dto.Submodule, err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	repo, err := vcs.Open(p.absPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if repo == nil {
+		return nil, vcs.ErrNotRepository
+	}
+
+	// remotes are looked up with the credentials kept in the secret store, like fetches and pulls
+	return vcs.Submodules(repo, remote, secrets.Lookup)
+}
+
+// loadSubProjects returns the paths of the stored sub-projects of parents which still exist, refreshing what is
+// detected in them. The branch is the one of the parent, scanned just before.
+func (p *Projects) loadSubProjects(parents []string) []string {
+	stored, err := database.Service().GetAllProjects()
+	if err != nil {
+		if !errors.Is(err, database.ErrNoRecords) {
+			p.log.ErrorFields("Unable to read sub-projects", platform.Fields{"error": err})
+		}
+
+		return nil
+	}
+
+	listed := make(map[string]bool, len(parents))
+	for _, path := range parents {
+		listed[path] = true
+	}
+
+	branches := make(map[string]string, len(parents))
+
+	for _, project := range stored {
+		if listed[project.Path] {
+			branches[project.Path] = project.Branch
+		}
+	}
+
+	var paths []string
+
+	for _, project := range stored {
+		if project.Parent == "" || !listed[project.Parent] {
+			continue
+		}
+
+		abs := p.absPath(project.Path)
+
+		info, err := os.Stat(abs)
+		if err != nil || !info.IsDir() {
+			p.log.DebugFields("Sub-project no longer exists", platform.Fields{"path": project.Path})
+
+			continue
+		}
+
+		languages := detect.Languages(abs)
+
+		err = database.Service().UpsertProject(map[string]interface{}{
+			"path":      project.Path,
+			"languages": languages,
+			"auto_tags": detect.Tags(languages),
+			"branch":    branches[project.Parent],
+		})
+		if err != nil {
+			p.log.ErrorFields("Error while upserting sub-project", platform.Fields{"path": project.Path, "error": err})
+		}
+
+		paths = append(paths, project.Path)
+	}
+
+	return paths
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/path"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+)
+
+// Validate does various forms of validation
+type Validate struct {
+	runtime *platform.Runtime
+	log     platform.Logger
+	bus     *events.Bus
+}
+
+func NewValidator() *Validate {
+	return new(Validate)
+}
+
+func (v *Validate) start(runtime *platform.Runtime) error {
+	v.runtime = runtime
+	v.log = v.runtime.Log("validation")
+	v.bus = events.NewBus(v.runtime.Events, v.log)
+
+	v.registerEvents()
+
+	return nil
+}
+
+// Registers events which can be called via the wails runtime
+func (v *Validate) registerEvents() {
+	// validate.config validates an entire configuration payload at once and emits an event with the errors payload
+	v.bus.Handle(events.ValidateConfig, func(payload interface{}) error {
+		v.bus.Emit(events.ValidateConfigCompleted, v.Configuration(payload.(map[string]interface{})))
+
+		return nil
+	})
+}
+
+// Configuration validates the keys present in cfg against the config schema and returns an error for every invalid key
+func (v *Validate) Configuration(cfg map[string]interface{}) []dto.FieldError {
+	errors := config.Validate(cfg)
+
+	v.log.DebugFields("Validated config", platform.Fields{"errors": errors})
+
+	return errors
+}
+
+// ProjectDir validates a project directory
+func (v *Validate) ProjectDir(projectDir string) (_ bool, err error) {
+	defer failure.Wrap(&err)
+
+	abs, err := path.ExpandAndValidate(projectDir)
+	if err != nil {
+		v.log.DebugFields("Validation failed on project directory", platform.Fields{"path": projectDir, "error": err})
+
+		return false, err
+	}
+
+	v.log.DebugFields("Target directory exists", platform.Fields{"path": abs})
+
+	return true, nil
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/vcs"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Worktrees lists the git worktrees of the project at path, the main worktree first. The project can be any of them.
+func (p *Projects) Worktrees(path string) (_ []dto.Worktree, err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	worktrees, err := vcs.Worktrees(p.absPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range worktrees {
+		worktrees[i].Project, _ = p.relPath(worktrees[i].Path)
+	}
+
+	return worktrees, nil
+}
+
+// Branches lists the local branches of the project at path, for picking the branch of a new worktree.
+func (p *Projects) Branches(path string) (_ []string, err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	repo, err := vcs.Open(p.absPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if repo == nil {
+		return nil, vcs.ErrNotRepository
+	}
+
+	branches, err := vcs.Branches(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{}, branches...), nil
+}
+
+// AddWorktree creates a linked worktree of the project at path from a dto.WorktreeRequest decoded from data. The
+// projects are rescanned afterwards, so a worktree created in the project directory is listed right away.
+func (p *Projects) AddWorktree(path string, data map[string]interface{}) (err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return ErrProjectNotFound
+	}
+
+	var req dto.WorktreeRequest
+
+	err = mapstructure.Decode(data, &req)
+	if err != nil {
+		return fmt.Errorf("unable to decode worktree request: %w", err)
+	}
+
+	// branches like feature/x would otherwise nest the worktree in a directory
+	dir := filepath.Base(path) + "-" + strings.ReplaceAll(req.Branch, "/", "-")
+	if req.Dir != "" {
+		dir = req.Dir
+	}
+
+	// relative directories are in the project directory, not the working directory of proman
+	if !filepath.IsAbs(dir) {
+		dir = p.absPath(dir)
+	}
+
+	p.log.DebugFields("Adding worktree", platform.Fields{"path": path, "dir": dir, "branch": req.Branch})
+
+	err = vcs.AddWorktree(p.absPath(path), dir, req.Branch, req.Create)
+	if err != nil {
+		return err
+	}
+
+	return p.rescan()
+}
+
+// RemoveWorktree removes the linked worktree at dir, an absolute path, of the project at path. Unless force is set a
+// worktree with changes is kept.
+func (p *Projects) RemoveWorktree(path, dir string, force bool) (err error) {
+	defer failure.Wrap(&err)
+
+	if _, ok := p.find(path); !ok {
+		return ErrProjectNotFound
+	}
+
+	p.log.DebugFields("Removing worktree", platform.Fields{"path": path, "dir": dir, "force": force})
+
+	err = vcs.RemoveWorktree(p.absPath(path), dir, force)
+	if err != nil {
+		return err
+	}
+
+	return p.rescan()
+}
+
+// worktreeOf returns the main worktree of the project at path when it is a linked worktree, see dto.Project.WorktreeOf.
+func (p *Projects) worktreeOf(path string) string {
+	main, err := vcs.MainWorktree(p.absPath(path))
+	if err != nil {
+		p.log.ErrorFields("Unable to read worktree", platform.Fields{"path": path, "error": err})
+
+		return ""
+	}
+
+	if rel, ok := p.relPath(main); ok {
+		return rel
+	}
+
+	return main
+}
+
+// relPath converts the absolute path of a directory in the project directory into its project path.
+func (p *Projects) relPath(abs string) (string, bool) {
+	p.mu.RLock()
+	root := p.root
+	p.mu.RUnlock()
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") || strings.ContainsRune(rel, filepath.Separator) {
+		return "", false
+	}
+
+	return rel, true
+}
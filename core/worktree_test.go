@@ -0,0 +1,96 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitRepo creates a repository at path with a single commit.
+func commitRepo(t *testing.T, path string) {
+	t.Helper()
+
+	repo, err := git.PlainInit(path, false)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(path, "readme.md"), []byte("# app\n"), 0o600)
+	}
+
+	var wt *git.Worktree
+	if err == nil {
+		wt, err = repo.Worktree()
+	}
+
+	if err == nil {
+		_, err = wt.Add("readme.md")
+	}
+
+	if err == nil {
+		_, err = wt.Commit("initial", &git.CommitOptions{
+			Author: &object.Signature{Name: "proman", Email: "proman@example.com", When: time.Now()},
+		})
+	}
+
+	if err != nil {
+		t.Fatalf("unable to create repository: %s", err)
+	}
+}
+
+func TestAddWorktreeDir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is required to manage worktrees")
+	}
+
+	dir := newProjectDir(t)
+	commitRepo(t, filepath.Join(dir, "app"))
+
+	app := startApp(t, dir, "")
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string
+	}{
+		{
+			name: "default",
+			data: map[string]interface{}{"branch": "feature/x", "create": true},
+			want: filepath.Join(dir, "app-feature-x"),
+		},
+		{
+			name: "relative",
+			data: map[string]interface{}{"branch": "relative", "create": true, "dir": "trees/app-relative"},
+			want: filepath.Join(dir, "trees", "app-relative"),
+		},
+		{
+			name: "absolute",
+			data: map[string]interface{}{"branch": "absolute", "create": true, "dir": filepath.Join(dir, "app-abs")},
+			want: filepath.Join(dir, "app-abs"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := app.Projects.AddWorktree("app", tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(tt.want, git.GitDirName)); err != nil {
+				t.Errorf("no worktree at %s: %s", tt.want, err)
+			}
+		})
+	}
+
+	worktrees, err := app.Projects.Worktrees("app")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(worktrees) != len(tests)+1 {
+		t.Errorf("%d worktrees, want the main one and %d linked", len(worktrees), len(tests))
+	}
+}
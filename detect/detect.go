@@ -0,0 +1,423 @@
+// Package detect detects the languages, toolchain versions and frameworks used by a project from its manifest files.
+package detect
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// detector inspects a project directory for a single language. It returns false if the language isn't used.
+type detector func(dir string) (dto.Language, bool)
+
+var detectors = []detector{
+	detectGo,
+	detectNode,
+	detectTypeScript,
+	detectRust,
+	detectPython,
+	detectJava,
+	detectRuby,
+	detectPHP,
+}
+
+// Languages returns the languages detected in the project directory dir, in a stable order. The result is never nil
+// so that it clears previously detected languages when stored.
+func Languages(dir string) []dto.Language {
+	langs := []dto.Language{}
+
+	for _, detect := range detectors {
+		lang, ok := detect(dir)
+		if ok {
+			langs = append(langs, lang)
+		}
+	}
+
+	return langs
+}
+
+// Tags returns the automatic tags for the detected languages: one per language and one per framework. Like Languages,
+// the result is never nil.
+func Tags(langs []dto.Language) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+
+	for _, lang := range langs {
+		for _, tag := range append([]string{lang.Name}, lang.Frameworks...) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+var (
+	goVersion     = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+	goToolchain   = regexp.MustCompile(`(?m)^toolchain\s+go(\S+)`)
+	rustVersion   = regexp.MustCompile(`(?m)^rust-version\s*=\s*"([^"]+)"`)
+	rustChannel   = regexp.MustCompile(`(?m)^channel\s*=\s*"([^"]+)"`)
+	pythonVersion = regexp.MustCompile(`(?m)^(?:requires-python|python)\s*=\s*"([^"]+)"`)
+	mavenVersion  = regexp.MustCompile(`<(?:java\.version|maven\.compiler\.release|maven\.compiler\.source)>([^<]+)<`)
+	gradleVersion = regexp.MustCompile(`(?:sourceCompatibility|languageVersion)\s*[=.(]\s*(?:JavaVersion\.VERSION_|JavaLanguageVersion\.of\()?['"]?([\d._]+)`)
+	rubyVersion   = regexp.MustCompile(`(?m)^ruby\s+['"]([^'"]+)['"]`)
+)
+
+// frameworks maps a dependency name found in a manifest to the framework tag it implies. Go modules are named without
+// their major version suffix and java dependencies by their group.
+var frameworks = map[string]map[string]string{
+	"go": {
+		"github.com/gin-gonic/gin":  "gin",
+		"github.com/labstack/echo":  "echo",
+		"github.com/gofiber/fiber":  "fiber",
+		"github.com/spf13/cobra":    "cobra",
+		"github.com/wailsapp/wails": "wails",
+	},
+	"node": {
+		"react":         "react",
+		"vue":           "vue",
+		"svelte":        "svelte",
+		"next":          "next",
+		"@angular/core": "angular",
+		"express":       "express",
+		"electron":      "electron",
+	},
+	"rust": {
+		"actix-web": "actix",
+		"rocket":    "rocket",
+		"tokio":     "tokio",
+		"tauri":     "tauri",
+	},
+	"python": {
+		"django":  "django",
+		"flask":   "flask",
+		"fastapi": "fastapi",
+	},
+	"java": {
+		"org.springframework.boot": "spring",
+		"io.quarkus":               "quarkus",
+	},
+	"ruby": {
+		"rails":   "rails",
+		"sinatra": "sinatra",
+	},
+	"php": {
+		"laravel/framework":        "laravel",
+		"symfony/symfony":          "symfony",
+		"symfony/framework-bundle": "symfony",
+	},
+}
+
+var (
+	goRequire       = regexp.MustCompile(`(?m)^(?:require\s+)?\s*([^\s()]+)\s+v\d\S*`)
+	goMajorVersion  = regexp.MustCompile(`/v\d+$`)
+	cargoSection    = regexp.MustCompile(`^\[+\s*([^\]]+?)\s*\]+`)
+	cargoKey        = regexp.MustCompile(`^\s*"?([A-Za-z0-9_-]+)"?\s*=`)
+	pythonLine      = regexp.MustCompile(`(?m)^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:$|[<>=!~;\[@])`)
+	pythonQuoted    = regexp.MustCompile(`["']([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:[<>=!~;\[@]|["'])`)
+	mavenGroup      = regexp.MustCompile(`<groupId>\s*([^<\s]+)\s*</groupId>`)
+	gradleReference = regexp.MustCompile(`['"]([A-Za-z0-9_.-]+)(?::|['"])`)
+	rubyGem         = regexp.MustCompile(`(?m)^\s*gem\s+['"]([^'"]+)['"]`)
+)
+
+func detectGo(dir string) (dto.Language, bool) {
+	data, ok := read(dir, "go.mod")
+	if !ok {
+		return dto.Language{}, false
+	}
+
+	version := match(goToolchain, data)
+	if version == "" {
+		version = match(goVersion, data)
+	}
+
+	return dto.Language{
+		Name:       "go",
+		Version:    version,
+		Manifest:   "go.mod",
+		Frameworks: findFrameworks("go", goDependencies(data)),
+	}, true
+}
+
+type packageJSON struct {
+	Engines         map[string]string `json:"engines"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+func detectNode(dir string) (dto.Language, bool) {
+	data, ok := read(dir, "package.json")
+	if !ok {
+		return dto.Language{}, false
+	}
+
+	lang := dto.Language{Name: "node", Manifest: "package.json"}
+
+	var pkg packageJSON
+	if json.Unmarshal([]byte(data), &pkg) != nil {
+		return lang, true
+	}
+
+	lang.Version = pkg.Engines["node"]
+
+	if nvmrc, ok := read(dir, ".nvmrc"); ok && lang.Version == "" {
+		lang.Version = strings.TrimSpace(nvmrc)
+	}
+
+	deps := make(map[string]bool, len(pkg.Dependencies)+len(pkg.DevDependencies))
+	for name := range pkg.Dependencies {
+		deps[name] = true
+	}
+
+	for name := range pkg.DevDependencies {
+		deps[name] = true
+	}
+
+	lang.Frameworks = findFrameworks("node", deps)
+
+	return lang, true
+}
+
+func detectTypeScript(dir string) (dto.Language, bool) {
+	if !exists(dir, "tsconfig.json") {
+		return dto.Language{}, false
+	}
+
+	return dto.Language{Name: "typescript", Manifest: "tsconfig.json"}, true
+}
+
+func detectRust(dir string) (dto.Language, bool) {
+	data, ok := read(dir, "Cargo.toml")
+	if !ok {
+		return dto.Language{}, false
+	}
+
+	version := match(rustVersion, data)
+
+	for _, name := range []string{"rust-toolchain.toml", "rust-toolchain"} {
+		toolchain, ok := read(dir, name)
+		if !ok || version != "" {
+			continue
+		}
+
+		version = match(rustChannel, toolchain)
+		if version == "" {
+			version = strings.TrimSpace(toolchain)
+		}
+	}
+
+	return dto.Language{
+		Name:       "rust",
+		Version:    version,
+		Manifest:   "Cargo.toml",
+		Frameworks: findFrameworks("rust", cargoDependencies(data)),
+	}, true
+}
+
+func detectPython(dir string) (dto.Language, bool) {
+	for _, manifest := range []string{"pyproject.toml", "setup.py", "setup.cfg", "requirements.txt", "Pipfile"} {
+		data, ok := read(dir, manifest)
+		if !ok {
+			continue
+		}
+
+		version := match(pythonVersion, data)
+		if pinned, ok := read(dir, ".python-version"); ok && version == "" {
+			version = strings.TrimSpace(pinned)
+		}
+
+		return dto.Language{
+			Name:       "python",
+			Version:    version,
+			Manifest:   manifest,
+			Frameworks: findFrameworks("python", pythonDependencies(data)),
+		}, true
+	}
+
+	return dto.Language{}, false
+}
+
+func detectJava(dir string) (dto.Language, bool) {
+	if data, ok := read(dir, "pom.xml"); ok {
+		return dto.Language{
+			Name:       "java",
+			Version:    match(mavenVersion, data),
+			Manifest:   "pom.xml",
+			Frameworks: findFrameworks("java", matchAll(mavenGroup, data)),
+		}, true
+	}
+
+	for _, manifest := range []string{"build.gradle", "build.gradle.kts"} {
+		data, ok := read(dir, manifest)
+		if !ok {
+			continue
+		}
+
+		return dto.Language{
+			Name:       "java",
+			Version:    strings.ReplaceAll(match(gradleVersion, data), "_", "."),
+			Manifest:   manifest,
+			Frameworks: findFrameworks("java", matchAll(gradleReference, data)),
+		}, true
+	}
+
+	return dto.Language{}, false
+}
+
+func detectRuby(dir string) (dto.Language, bool) {
+	data, ok := read(dir, "Gemfile")
+	if !ok {
+		return dto.Language{}, false
+	}
+
+	version := match(rubyVersion, data)
+	if pinned, ok := read(dir, ".ruby-version"); ok && version == "" {
+		version = strings.TrimSpace(pinned)
+	}
+
+	return dto.Language{
+		Name:       "ruby",
+		Version:    version,
+		Manifest:   "Gemfile",
+		Frameworks: findFrameworks("ruby", matchAll(rubyGem, data)),
+	}, true
+}
+
+type composerJSON struct {
+	Require map[string]string `json:"require"`
+}
+
+func detectPHP(dir string) (dto.Language, bool) {
+	data, ok := read(dir, "composer.json")
+	if !ok {
+		return dto.Language{}, false
+	}
+
+	lang := dto.Language{Name: "php", Manifest: "composer.json"}
+
+	var composer composerJSON
+	if json.Unmarshal([]byte(data), &composer) != nil {
+		return lang, true
+	}
+
+	lang.Version = composer.Require["php"]
+
+	deps := make(map[string]bool, len(composer.Require))
+	for name := range composer.Require {
+		deps[name] = true
+	}
+
+	lang.Frameworks = findFrameworks("php", deps)
+
+	return lang, true
+}
+
+// findFrameworks returns the sorted framework tags for the language whose dependencies are in deps.
+func findFrameworks(language string, deps map[string]bool) []string {
+	seen := make(map[string]bool)
+
+	var found []string
+
+	for dep, framework := range frameworks[language] {
+		if deps[dep] && !seen[framework] {
+			seen[framework] = true
+			found = append(found, framework)
+		}
+	}
+
+	sort.Strings(found)
+
+	return found
+}
+
+// goDependencies returns the modules required by a go.mod, without their major version suffix.
+func goDependencies(data string) map[string]bool {
+	deps := make(map[string]bool)
+	for _, m := range goRequire.FindAllStringSubmatch(data, -1) {
+		deps[goMajorVersion.ReplaceAllString(m[1], "")] = true
+	}
+
+	return deps
+}
+
+// cargoDependencies returns the crates listed in the dependency tables of a Cargo.toml, including those declared as
+// tables of their own such as [dependencies.tokio].
+func cargoDependencies(data string) map[string]bool {
+	deps := make(map[string]bool)
+	inDependencies := false
+
+	for _, line := range strings.Split(data, "\n") {
+		if m := cargoSection.FindStringSubmatch(line); m != nil {
+			section := m[1]
+			inDependencies = strings.HasSuffix(section, "dependencies")
+
+			if i := strings.Index(section, "dependencies."); i >= 0 {
+				deps[strings.Trim(section[i+len("dependencies."):], `"`)] = true
+			}
+
+			continue
+		}
+
+		if m := cargoKey.FindStringSubmatch(line); m != nil && inDependencies {
+			deps[m[1]] = true
+		}
+	}
+
+	return deps
+}
+
+// pythonDependencies returns the normalized names of the packages in a python manifest, whether listed one per line
+// as in requirements.txt or quoted as in pyproject.toml and setup.py.
+func pythonDependencies(data string) map[string]bool {
+	deps := make(map[string]bool)
+
+	for _, re := range []*regexp.Regexp{pythonLine, pythonQuoted} {
+		for name := range matchAll(re, data) {
+			deps[strings.ReplaceAll(strings.ToLower(name), "_", "-")] = true
+		}
+	}
+
+	return deps
+}
+
+// matchAll returns the set of the first submatch of every match of re in data.
+func matchAll(re *regexp.Regexp, data string) map[string]bool {
+	found := make(map[string]bool)
+	for _, m := range re.FindAllStringSubmatch(data, -1) {
+		found[m[1]] = true
+	}
+
+	return found
+}
+
+func match(re *regexp.Regexp, data string) string {
+	m := re.FindStringSubmatch(data)
+	if len(m) < 2 {
+		return ""
+	}
+
+	return strings.TrimSpace(m[1])
+}
+
+func read(dir, name string) (string, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
+
+func exists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+
+	return err == nil
+}
@@ -0,0 +1,102 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFrameworks(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		data     string
+		want     []string
+	}{
+		{
+			name:     "node exact names",
+			manifest: "package.json",
+			data: `{"dependencies": {"preact": "10", "vuex": "4", "next-auth": "4", "express-session": "1"},
+				"devDependencies": {"svelte": "3"}}`,
+			want: []string{"svelte"},
+		},
+		{
+			name:     "node frameworks",
+			manifest: "package.json",
+			data:     `{"dependencies": {"react": "18", "next": "13"}, "devDependencies": {"electron": "20"}}`,
+			want:     []string{"electron", "next", "react"},
+		},
+		{
+			name:     "go modules",
+			manifest: "go.mod",
+			data: "module example.com/app\n\ngo 1.16\n\nrequire github.com/labstack/echo/v4 v4.6.1\n\n" +
+				"require (\n\tgithub.com/gin-gonic/gin v1.7.4\n\tgithub.com/spf13/cobra-cli v1.3.0 // indirect\n)\n",
+			want: []string{"echo", "gin"},
+		},
+		{
+			name:     "cargo dependency tables",
+			manifest: "Cargo.toml",
+			data: "[package]\nname = \"rocket\"\n\n[dependencies]\ntokio = { version = \"1\" }\n" +
+				"tokio-util = \"0.7\"\n\n[dependencies.actix-web]\nversion = \"4\"\n",
+			want: []string{"actix", "tokio"},
+		},
+		{
+			name:     "requirements",
+			manifest: "requirements.txt",
+			data:     "Django>=4.0\nflask-cors==3.0\n# fastapi\n",
+			want:     []string{"django"},
+		},
+		{
+			name:     "pyproject",
+			manifest: "pyproject.toml",
+			data:     "[project]\ndescription = \"flask app\"\ndependencies = [\"fastapi>=0.95\", \"django-environ\"]\n",
+			want:     []string{"fastapi"},
+		},
+		{
+			name:     "gemfile",
+			manifest: "Gemfile",
+			data:     "source 'https://rubygems.org'\ngem 'rails', '~> 7.0'\ngem 'sinatra-contrib'\n",
+			want:     []string{"rails"},
+		},
+		{
+			name:     "maven groups",
+			manifest: "pom.xml",
+			data: "<dependency><groupId>org.springframework.boot</groupId>" +
+				"<artifactId>spring-boot-starter-web</artifactId></dependency>",
+			want: []string{"spring"},
+		},
+		{
+			name:     "gradle references",
+			manifest: "build.gradle",
+			data:     "dependencies {\n\timplementation 'io.quarkus:quarkus-resteasy:2.0'\n}\n",
+			want:     []string{"quarkus"},
+		},
+		{
+			name:     "composer",
+			manifest: "composer.json",
+			data:     `{"require": {"php": ">=8.1", "symfony/console": "6", "laravel/framework": "10"}}`,
+			want:     []string{"laravel"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			err := os.WriteFile(filepath.Join(dir, tt.manifest), []byte(tt.data), 0o600)
+			if err != nil {
+				t.Fatalf("unable to write manifest: %s", err)
+			}
+
+			langs := Languages(dir)
+			if len(langs) != 1 {
+				t.Fatalf("detected %v, want a single language", langs)
+			}
+
+			if !reflect.DeepEqual(langs[0].Frameworks, tt.want) {
+				t.Errorf("frameworks %v, want %v", langs[0].Frameworks, tt.want)
+			}
+		})
+	}
+}
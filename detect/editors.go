@@ -0,0 +1,79 @@
+package detect
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mattouille/proman/dto"
+)
+
+type knownEditor struct {
+	name string
+	// commands are looked up on PATH
+	commands []string
+	// apps are macOS application bundles, relative to /Applications and ~/Applications, with the launcher inside them
+	apps []string
+}
+
+// knownEditors are the editors Editors looks for, in the order they are suggested.
+var knownEditors = []knownEditor{
+	{name: "Visual Studio Code", commands: []string{"code"}, apps: []string{"Visual Studio Code.app/Contents/Resources/app/bin/code"}},
+	{name: "VSCodium", commands: []string{"codium"}, apps: []string{"VSCodium.app/Contents/Resources/app/bin/codium"}},
+	{name: "GoLand", commands: []string{"goland", "goland.sh"}, apps: []string{"GoLand.app/Contents/MacOS/goland"}},
+	{name: "IntelliJ IDEA", commands: []string{"idea", "idea.sh"}, apps: []string{"IntelliJ IDEA.app/Contents/MacOS/idea"}},
+	{name: "PyCharm", commands: []string{"pycharm", "pycharm.sh"}, apps: []string{"PyCharm.app/Contents/MacOS/pycharm"}},
+	{name: "WebStorm", commands: []string{"webstorm", "webstorm.sh"}, apps: []string{"WebStorm.app/Contents/MacOS/webstorm"}},
+	{name: "Sublime Text", commands: []string{"subl"}, apps: []string{"Sublime Text.app/Contents/SharedSupport/bin/subl"}},
+	{name: "Zed", commands: []string{"zed"}, apps: []string{"Zed.app/Contents/MacOS/cli"}},
+}
+
+// Editors returns the known editors installed on this machine. The returned slice is never nil.
+func Editors() []dto.Editor {
+	editors := []dto.Editor{}
+
+	for _, known := range knownEditors {
+		path := known.find()
+		if path == "" {
+			continue
+		}
+
+		editors = append(editors, dto.Editor{Name: known.name, Path: path})
+	}
+
+	return editors
+}
+
+func (e knownEditor) find() string {
+	for _, command := range e.commands {
+		path, err := exec.LookPath(command)
+		if err == nil {
+			return path
+		}
+	}
+
+	if runtime.GOOS != "darwin" {
+		return ""
+	}
+
+	dirs := []string{"/Applications"}
+
+	usr, err := user.Current()
+	if err == nil {
+		dirs = append(dirs, filepath.Join(usr.HomeDir, "Applications"))
+	}
+
+	for _, dir := range dirs {
+		for _, app := range e.apps {
+			path := filepath.Join(dir, app)
+
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
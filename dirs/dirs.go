@@ -0,0 +1,176 @@
+// Package dirs locates the directories proman keeps its files in. It follows the XDG base directory specification:
+// config lives in $XDG_CONFIG_HOME/proman, data such as the database in $XDG_DATA_HOME/proman and state in
+// $XDG_STATE_HOME/proman. Setting PROMAN_HOME keeps everything in that one directory instead.
+package dirs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+const (
+	// Name is the directory created inside each base directory
+	Name = "proman"
+	// HomeEnv overrides every base directory
+	HomeEnv = "PROMAN_HOME"
+)
+
+// DefaultPermissions are applied to directories created by Ensure.
+const DefaultPermissions = 0o775
+
+// Config returns the directory the config file lives in.
+func Config() (string, error) {
+	return resolve("XDG_CONFIG_HOME", ".config")
+}
+
+// Data returns the directory the database and other persistent data live in.
+func Data() (string, error) {
+	return resolve("XDG_DATA_HOME", ".local", "share")
+}
+
+// State returns the directory for state which may be lost, such as logs and lock files.
+func State() (string, error) {
+	return resolve("XDG_STATE_HOME", ".local", "state")
+}
+
+// Legacy returns the directory every file was kept in before the XDG base directories were honored.
+func Legacy() (string, error) {
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", Name), nil
+}
+
+// Ensure creates dir, and any parents, if it doesn't exist yet and returns it.
+func Ensure(dir string) (string, error) {
+	err := os.MkdirAll(dir, DefaultPermissions)
+	if err != nil {
+		return "", fmt.Errorf("unable to create %s: %w", dir, err)
+	}
+
+	return dir, nil
+}
+
+// resolve returns the proman directory inside the base directory named by env, falling back to fallback relative to
+// the home directory when env isn't set. The XDG specification requires the variables to be absolute, relative values
+// are ignored.
+func resolve(env string, fallback ...string) (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return filepath.Abs(home)
+	}
+
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, Name), nil
+	}
+
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(append(append([]string{home}, fallback...), Name)...), nil
+}
+
+// homeDir returns $HOME, or the home directory of the current user when it isn't set.
+func homeDir() (string, error) {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home, nil
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine current user: %w", err)
+	}
+
+	return usr.HomeDir, nil
+}
+
+// Migrate moves the file name from the legacy directory into dir. Nothing is moved when PROMAN_HOME is set, when dir
+// is the legacy directory, when the legacy file doesn't exist or when dir already has the file, so it is safe to call
+// on every start.
+func Migrate(name, dir string) error {
+	if os.Getenv(HomeEnv) != "" {
+		return nil
+	}
+
+	legacy, err := Legacy()
+	if err != nil {
+		return err
+	}
+
+	if filepath.Clean(legacy) == filepath.Clean(dir) {
+		return nil
+	}
+
+	src := filepath.Join(legacy, name)
+	dst := filepath.Join(dir, name)
+
+	if _, err := os.Stat(src); err != nil {
+		return nil
+	}
+
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+
+	_, err = Ensure(dir)
+	if err != nil {
+		return err
+	}
+
+	// a rename fails across file systems, in which case the file is copied instead
+	err = os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+
+	err = copyFile(src, dst)
+	if err != nil {
+		return fmt.Errorf("unable to migrate %s: %w", src, err)
+	}
+
+	return os.Remove(src)
+}
+
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// write next to the destination and rename, so a failed copy never leaves a partial file in place
+	tmp := dst + ".migrating"
+
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	err = out.Close()
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,269 @@
+package dirs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setenv sets the environment variable key for the duration of the test, unsetting it when value is blank.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	previous, ok := os.LookupEnv(key)
+
+	err := os.Unsetenv(key)
+	if err == nil && value != "" {
+		err = os.Setenv(key, value)
+	}
+
+	if err != nil {
+		t.Fatalf("unable to set %s: %s", key, err)
+	}
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, previous)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+// isolate points HOME at a temporary directory and clears the variables the directories are resolved from.
+func isolate(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+
+	setenv(t, "HOME", home)
+
+	for _, key := range []string{HomeEnv, "XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME"} {
+		setenv(t, key, "")
+	}
+
+	return home
+}
+
+func TestDirectories(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		config string
+		data   string
+		state  string
+	}{
+		{
+			name:   "defaults",
+			config: "~/.config/proman",
+			data:   "~/.local/share/proman",
+			state:  "~/.local/state/proman",
+		},
+		{
+			name: "xdg variables",
+			env: map[string]string{
+				"XDG_CONFIG_HOME": "/xdg/config",
+				"XDG_DATA_HOME":   "/xdg/data",
+				"XDG_STATE_HOME":  "/xdg/state",
+			},
+			config: "/xdg/config/proman",
+			data:   "/xdg/data/proman",
+			state:  "/xdg/state/proman",
+		},
+		{
+			name:   "relative xdg variables are ignored",
+			env:    map[string]string{"XDG_CONFIG_HOME": "config", "XDG_DATA_HOME": "./data"},
+			config: "~/.config/proman",
+			data:   "~/.local/share/proman",
+			state:  "~/.local/state/proman",
+		},
+		{
+			name:   "proman home overrides everything",
+			env:    map[string]string{HomeEnv: "/opt/proman", "XDG_CONFIG_HOME": "/xdg/config"},
+			config: "/opt/proman",
+			data:   "/opt/proman",
+			state:  "/opt/proman",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolate(t)
+
+			for key, value := range tt.env {
+				setenv(t, key, value)
+			}
+
+			for _, dir := range []struct {
+				name    string
+				resolve func() (string, error)
+				want    string
+			}{
+				{"config", Config, tt.config},
+				{"data", Data, tt.data},
+				{"state", State, tt.state},
+			} {
+				got, err := dir.resolve()
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				want := filepath.FromSlash(dir.want)
+				if want[0] == '~' {
+					want = filepath.Join(home, want[1:])
+				}
+
+				if got != want {
+					t.Errorf("%s directory %s, want %s", dir.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// writeFile writes data to path, creating its directory.
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = ioutil.WriteFile(path, []byte(data), 0o600)
+	}
+
+	if err != nil {
+		t.Fatalf("unable to write %s: %s", path, err)
+	}
+}
+
+// readFile returns the contents of path, or a blank string when it doesn't exist.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+
+	if err != nil {
+		t.Fatalf("unable to read %s: %s", path, err)
+	}
+
+	return string(data)
+}
+
+func TestMigrate(t *testing.T) {
+	home := isolate(t)
+
+	legacy := filepath.Join(home, ".config", Name, "store.db")
+	data := filepath.Join(home, ".local", "share", Name)
+
+	writeFile(t, legacy, "legacy")
+
+	err := Migrate("store.db", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := readFile(t, filepath.Join(data, "store.db")); got != "legacy" {
+		t.Errorf("migrated file holds %q, want %q", got, "legacy")
+	}
+
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy file still exists")
+	}
+
+	// running again finds nothing to move and leaves the migrated file alone
+	err = Migrate("store.db", data)
+	if err != nil {
+		t.Fatalf("unexpected error on a second run: %s", err)
+	}
+
+	if got := readFile(t, filepath.Join(data, "store.db")); got != "legacy" {
+		t.Errorf("migrated file holds %q after a second run, want %q", got, "legacy")
+	}
+}
+
+func TestMigrateKeepsExistingFile(t *testing.T) {
+	home := isolate(t)
+
+	legacy := filepath.Join(home, ".config", Name, "store.db")
+	data := filepath.Join(home, ".local", "share", Name)
+
+	writeFile(t, legacy, "legacy")
+	writeFile(t, filepath.Join(data, "store.db"), "current")
+
+	err := Migrate("store.db", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := readFile(t, filepath.Join(data, "store.db")); got != "current" {
+		t.Errorf("existing file was overwritten with %q", got)
+	}
+
+	if got := readFile(t, legacy); got != "legacy" {
+		t.Errorf("legacy file holds %q, want it left in place", got)
+	}
+}
+
+func TestMigrateSkipped(t *testing.T) {
+	tests := []struct {
+		name  string
+		home  bool
+		inDir bool
+	}{
+		{name: "proman home set", home: true},
+		{name: "target is the legacy directory", inDir: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := isolate(t)
+
+			legacyDir := filepath.Join(home, ".config", Name)
+			legacy := filepath.Join(legacyDir, "config.toml")
+
+			writeFile(t, legacy, "legacy")
+
+			dir := filepath.Join(home, "elsewhere")
+			if tt.inDir {
+				dir = legacyDir + string(filepath.Separator)
+			}
+
+			if tt.home {
+				setenv(t, HomeEnv, dir)
+			}
+
+			err := Migrate("config.toml", dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got := readFile(t, legacy); got != "legacy" {
+				t.Errorf("legacy file was moved")
+			}
+
+			if !tt.inDir {
+				if got := readFile(t, filepath.Join(dir, "config.toml")); got != "" {
+					t.Errorf("file was migrated to %s", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateMissingLegacyFile(t *testing.T) {
+	home := isolate(t)
+
+	data := filepath.Join(home, ".local", "share", Name)
+
+	err := Migrate("store.db", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := os.Stat(data); !os.IsNotExist(err) {
+		t.Errorf("%s was created without a file to migrate", data)
+	}
+}
@@ -0,0 +1,380 @@
+// Package disk measures project sizes and removes build artifacts.
+package disk
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// ArtifactDirs are directory names known to hold build output or caches which can be recreated, each with the
+// manifests of the ecosystems which put it there. A directory is only an artifact when one of them sits beside it.
+var ArtifactDirs = map[string][]string{
+	"node_modules": {"package.json"},
+	"target":       {"Cargo.toml", "pom.xml"},
+	"vendor":       {"go.mod", "composer.json"},
+	"dist":         {"package.json"},
+	".venv":        {"pyproject.toml", "requirements.txt", "setup.py", "Pipfile"},
+}
+
+var (
+	ErrOutsideProject = errors.New("path is outside of the project")
+	ErrNoRepository   = errors.New("build artifacts are only cleaned in git repositories")
+)
+
+// Measure walks the project at root and returns its size broken down into tracked source, the .git directory, build
+// artifacts and everything else. repo may be nil for projects which aren't repositories.
+func Measure(root string, repo *git.Repository) (dto.DiskUsage, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return dto.DiskUsage{}, err
+	}
+
+	tracked, err := trackedFiles(repo)
+	if err != nil {
+		return dto.DiskUsage{}, err
+	}
+
+	usage := dto.DiskUsage{
+		Artifacts: make(map[string]int64),
+		ScannedAt: time.Now(),
+		ModTime:   info.ModTime(),
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// unreadable entries are skipped rather than failing the whole measurement
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			latest(&usage.ModTime, d)
+
+			switch {
+			case d.Name() == git.GitDirName && filepath.Dir(rel) == ".":
+				usage.Git, err = Size(path)
+			case isArtifact(path):
+				usage.Artifacts[filepath.ToSlash(rel)], err = Size(path)
+			default:
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			return filepath.SkipDir
+		}
+
+		size := fileSize(d)
+
+		if tracked == nil || tracked[filepath.ToSlash(rel)] {
+			usage.Source += size
+		} else {
+			usage.Other += size
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.DiskUsage{}, err
+	}
+
+	usage.Total = usage.Source + usage.Git + usage.Other + usage.ArtifactSize()
+
+	return usage, nil
+}
+
+// ModTime returns the latest modification time of the directories of the project at root, the time Measure records
+// in dto.DiskUsage.ModTime. Neither the .git directory nor the inside of artifact directories are looked at.
+func ModTime(root string) (time.Time, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	mod := info.ModTime()
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if path == root {
+			return nil
+		}
+
+		latest(&mod, d)
+
+		if (d.Name() == git.GitDirName && filepath.Dir(path) == root) || isArtifact(path) {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return mod, err
+}
+
+// Size returns the combined size of all regular files underneath path. Symlinks are not followed.
+func Size(path string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() {
+			size += fileSize(d)
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+// Clean removes the artifact directories found in usage from the project at root, which must be a git repository.
+// Directories which aren't ignored by git, contain tracked files, are symlinks or resolve outside of the project are
+// skipped. Nothing is removed when dryRun is true.
+func Clean(root string, repo *git.Repository, usage dto.DiskUsage, dryRun bool) (dto.CleanResult, error) {
+	result := dto.CleanResult{
+		DryRun:  dryRun,
+		Removed: make(map[string]int64),
+		Skipped: make(map[string]string),
+	}
+
+	if repo == nil {
+		return result, ErrNoRepository
+	}
+
+	tracked, err := trackedFiles(repo)
+	if err != nil {
+		return result, err
+	}
+
+	ignored, err := ignoreMatcher(root, repo, usage)
+	if err != nil {
+		return result, err
+	}
+
+	for rel, size := range usage.Artifacts {
+		reason, err := unsafeToRemove(root, rel, tracked, ignored)
+		if err != nil {
+			return result, err
+		}
+
+		if reason != "" {
+			result.Skipped[rel] = reason
+
+			continue
+		}
+
+		if !dryRun {
+			err := os.RemoveAll(filepath.Join(root, filepath.FromSlash(rel)))
+			if err != nil {
+				return result, err
+			}
+		}
+
+		result.Removed[rel] = size
+		result.Freed += size
+	}
+
+	return result, nil
+}
+
+// unsafeToRemove returns the reason an artifact directory must not be removed, or a blank string if it is safe.
+func unsafeToRemove(root, rel string, tracked map[string]bool, ignored gitignore.Matcher) (string, error) {
+	path := filepath.Join(root, filepath.FromSlash(rel))
+
+	if !isArtifact(path) {
+		return "not a known artifact directory", nil
+	}
+
+	target, err := filepath.Rel(root, path)
+	if err != nil || target == ".." || strings.HasPrefix(target, ".."+string(filepath.Separator)) {
+		return "", ErrOutsideProject
+	}
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return "no longer exists", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return "is a symlink", nil
+	}
+
+	if !info.IsDir() {
+		return "is not a directory", nil
+	}
+
+	prefix := filepath.ToSlash(target) + "/"
+	for file := range tracked {
+		if strings.HasPrefix(file, prefix) {
+			return "contains files tracked by git", nil
+		}
+	}
+
+	if !ignored.Match(strings.Split(filepath.ToSlash(target), "/"), true) {
+		return "is not ignored by git", nil
+	}
+
+	return "", nil
+}
+
+// isArtifact reports whether the directory at path is an artifact directory, named as one and next to a manifest of an
+// ecosystem which creates it.
+func isArtifact(path string) bool {
+	for _, manifest := range ArtifactDirs[filepath.Base(path)] {
+		info, err := os.Stat(filepath.Join(filepath.Dir(path), manifest))
+		if err == nil && !info.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignoreMatcher returns a matcher for the gitignore patterns which apply to the artifact directories in usage: those
+// of the repository's info/exclude file and of the .gitignore files of the directories leading to each of them.
+func ignoreMatcher(root string, repo *git.Repository, usage dto.DiskUsage) (gitignore.Matcher, error) {
+	var patterns []gitignore.Pattern
+
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		ps, err := readPatterns(filepath.Join(storage.Filesystem().Root(), "info", "exclude"), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, ps...)
+	}
+
+	read := make(map[string]bool)
+
+	for rel := range usage.Artifacts {
+		parts := strings.Split(rel, "/")
+
+		// parents are read before children, as later patterns take precedence
+		for i := 0; i < len(parts); i++ {
+			domain := parts[:i]
+
+			dir := strings.Join(domain, "/")
+			if read[dir] {
+				continue
+			}
+
+			read[dir] = true
+
+			ps, err := readPatterns(filepath.Join(root, filepath.FromSlash(dir), ".gitignore"), domain)
+			if err != nil {
+				return nil, err
+			}
+
+			patterns = append(patterns, ps...)
+		}
+	}
+
+	return gitignore.NewMatcher(patterns), nil
+}
+
+// readPatterns reads the gitignore patterns of the file at path, which apply to the directory domain.
+func readPatterns(path string, domain []string) ([]gitignore.Pattern, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var patterns []gitignore.Pattern
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+
+	return patterns, scanner.Err()
+}
+
+// latest raises mod to the modification time of the directory d if it is later.
+func latest(mod *time.Time, d fs.DirEntry) {
+	info, err := d.Info()
+	if err == nil && info.ModTime().After(*mod) {
+		*mod = info.ModTime()
+	}
+}
+
+// trackedFiles returns the set of slash separated paths in the repository index, or nil when repo is nil.
+func trackedFiles(repo *git.Repository) (map[string]bool, error) {
+	if repo == nil {
+		return nil, nil
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[string]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		tracked[entry.Name] = true
+	}
+
+	return tracked, nil
+}
+
+func fileSize(d fs.DirEntry) int64 {
+	info, err := d.Info()
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+
+	return info.Size()
+}
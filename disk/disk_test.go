@@ -0,0 +1,240 @@
+package disk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// writeFiles creates the files, relative to root, with their parent directories.
+func writeFiles(t *testing.T, root string, files ...string) {
+	t.Helper()
+
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file))
+
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(file), 0o600)
+		}
+
+		if err != nil {
+			t.Fatalf("unable to write %s: %s", file, err)
+		}
+	}
+}
+
+// initRepo makes root a git repository with the files tracked in its index.
+func initRepo(t *testing.T, root string, tracked ...string) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("unable to init repository: %s", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("unable to open worktree: %s", err)
+	}
+
+	for _, file := range tracked {
+		_, err = wt.Add(file)
+		if err != nil {
+			t.Fatalf("unable to add %s: %s", file, err)
+		}
+	}
+
+	return repo
+}
+
+func TestMeasureArtifactLocations(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root,
+		"package.json", "node_modules/a/index.js",
+		"web/package.json", "web/node_modules/b/index.js", "web/dist/app.js",
+		"docs/vendor/style.css", "docs/target/out.html",
+		"go.mod", "vendor/modules.txt",
+	)
+
+	usage, err := Measure(root, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"node_modules", "vendor", "web/dist", "web/node_modules"}
+
+	var got []string
+	for rel := range usage.Artifacts {
+		got = append(got, rel)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("artifacts %v, want %v", got, want)
+	}
+
+	for _, rel := range want {
+		if _, ok := usage.Artifacts[rel]; !ok {
+			t.Errorf("%s is not an artifact, artifacts are %v", rel, got)
+		}
+	}
+}
+
+func TestCleanWithoutRepository(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, "package.json", "node_modules/a/index.js")
+
+	usage, err := Measure(root, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = Clean(root, nil, usage, false)
+	if !errors.Is(err, ErrNoRepository) {
+		t.Fatalf("error %v, want %v", err, ErrNoRepository)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "node_modules")); err != nil {
+		t.Errorf("node_modules was removed: %s", err)
+	}
+}
+
+func TestClean(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root,
+		"package.json", "node_modules/a/index.js",
+		"web/package.json", "web/node_modules/b/index.js", "web/dist/app.js",
+		"go.mod", "vendor/modules.txt",
+		"api/composer.json", "api/vendor/lib.php",
+	)
+
+	err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("node_modules/\n"), 0o600)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(root, "web", ".gitignore"), []byte("/dist\n"), 0o600)
+	}
+
+	if err != nil {
+		t.Fatalf("unable to write .gitignore: %s", err)
+	}
+
+	repo := initRepo(t, root, ".gitignore", "web/.gitignore", "go.mod", "vendor/modules.txt")
+
+	usage, err := Measure(root, repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err := Clean(root, repo, usage, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	removed := []string{"node_modules", "web/dist", "web/node_modules"}
+	for _, rel := range removed {
+		if _, ok := result.Removed[rel]; !ok {
+			t.Errorf("%s was not removed, skipped because %q", rel, result.Skipped[rel])
+		}
+
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel))); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", rel)
+		}
+	}
+
+	skipped := map[string]string{
+		"vendor":     "contains files tracked by git",
+		"api/vendor": "is not ignored by git",
+	}
+	for rel, reason := range skipped {
+		if result.Skipped[rel] != reason {
+			t.Errorf("%s skipped because %q, want %q", rel, result.Skipped[rel], reason)
+		}
+
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel))); err != nil {
+			t.Errorf("%s was removed", rel)
+		}
+	}
+}
+
+func TestCleanDryRun(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, "package.json", "node_modules/a/index.js")
+
+	err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("node_modules\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write .gitignore: %s", err)
+	}
+
+	repo := initRepo(t, root, ".gitignore", "package.json")
+
+	usage, err := Measure(root, repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err := Clean(root, repo, usage, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if result.Freed != usage.Artifacts["node_modules"] || result.Freed == 0 {
+		t.Errorf("freed %d, want %d", result.Freed, usage.Artifacts["node_modules"])
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "node_modules")); err != nil {
+		t.Errorf("node_modules was removed on a dry run: %s", err)
+	}
+}
+
+func TestModTime(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, "src/pkg/main.go", "package.json", "node_modules/a/index.js")
+
+	past := time.Now().Add(-time.Hour)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		return os.Chtimes(path, past, past)
+	})
+	if err != nil {
+		t.Fatalf("unable to set times: %s", err)
+	}
+
+	usage, err := Measure(root, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	mod, err := ModTime(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !mod.Equal(usage.ModTime) {
+		t.Fatalf("mod time %s, measured %s", mod, usage.ModTime)
+	}
+
+	// changes inside artifact directories aren't looked at
+	writeFiles(t, root, "node_modules/a/b/extra.js")
+
+	if mod, _ = ModTime(root); !mod.Equal(usage.ModTime) {
+		t.Errorf("mod time changed to %s by an artifact", mod)
+	}
+
+	writeFiles(t, root, "src/pkg/extra.go")
+
+	if mod, _ = ModTime(root); !mod.After(usage.ModTime) {
+		t.Errorf("mod time %s didn't change with a nested file, measured %s", mod, usage.ModTime)
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import "errors"
+
+// Action is a user defined command run against a project, such as opening it in another tool. An action with neither
+// Tag nor Project applies to every project.
+type Action struct {
+	// Name identifies the action
+	Name string `json:"name" mapstructure:"name"`
+	// Command is the command line to run from the project directory. It is split into arguments on whitespace outside
+	// of quotes before the variables {path}, {name}, {remote} and {branch} are replaced, so values containing spaces
+	// stay one argument. A command which is only an http(s) url is opened in the browser instead.
+	Command string `json:"command" mapstructure:"command"`
+	// Tag limits the action to projects carrying the tag
+	Tag string `json:"tag,omitempty" mapstructure:"tag"`
+	// Project limits the action to the project at the path
+	Project string `json:"project,omitempty" mapstructure:"project"`
+}
+
+// Validate checks that the action can be stored.
+func (a Action) Validate() error {
+	if a.Name == "" {
+		return errors.New("action name is required")
+	}
+
+	if a.Command == "" {
+		return errors.New("action command is required")
+	}
+
+	if a.Tag != "" && a.Project != "" {
+		return errors.New("an action applies to a tag or to a project, not both")
+	}
+
+	return nil
+}
+
+// ActionRun asks for an action to be run against a project.
+type ActionRun struct {
+	// Path is the project
+	Path string `json:"path" mapstructure:"path"`
+	// Action is the name of the action
+	Action string `json:"action" mapstructure:"action"`
+}
+
+// Validate checks that the project and the action are named.
+func (r ActionRun) Validate() error {
+	if r.Path == "" {
+		return errors.New("project path is required")
+	}
+
+	if r.Action == "" {
+		return errors.New("action name is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package dto
+
+// BatchRequest describes a git operation to run across a selection of projects.
+type BatchRequest struct {
+	// Operation is the git operation to run, one of status, fetch, pull or checkout
+	Operation string `json:"operation" mapstructure:"operation"`
+	// Selector picks the projects the operation is run against
+	Selector ProjectSelector `json:"selector" mapstructure:"selector"`
+	// Branch is the branch to switch to when Operation is checkout
+	Branch string `json:"branch,omitempty" mapstructure:"branch"`
+	// Concurrency is the maximum number of repositories operated on at once
+	Concurrency int `json:"concurrency,omitempty" mapstructure:"concurrency"`
+}
+
+// BatchResult is the outcome of a batch operation for a single project.
+type BatchResult struct {
+	// Path is the path of the project from the root Project Directory
+	Path string `json:"path"`
+	// Operation is the git operation that was run
+	Operation string `json:"operation"`
+	// Message is a short human readable summary of the outcome
+	Message string `json:"message,omitempty"`
+	// Error is set when the operation failed
+	Error string `json:"error,omitempty"`
+	// Duration is how long the operation took in milliseconds
+	Duration int64 `json:"duration"`
+}
+
+// BatchProgress is emitted each time a project in a batch finishes.
+type BatchProgress struct {
+	Result BatchResult `json:"result"`
+	Done   int         `json:"done"`
+	Total  int         `json:"total"`
+}
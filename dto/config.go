@@ -3,4 +3,29 @@ package dto
 // ConfigSchema represents the config keys and values
 type ConfigSchema struct {
 	ProjectDirectory string `mapstructure:"project_directory" json:"project_directory"`
+	// SetupComplete is set once first run setup has been finished or skipped
+	SetupComplete bool `mapstructure:"setup_complete" json:"setup_complete"`
+	// StartMinimized starts proman with its window minimized. Only the wails v2 shell supports it, wails v1 can't
+	// minimize its window.
+	StartMinimized bool `mapstructure:"start_minimized" json:"start_minimized"`
+	// HiddenPatterns are glob patterns matched against project paths. Matching projects are always hidden.
+	HiddenPatterns []string `mapstructure:"hidden_patterns" json:"hidden_patterns"`
+	// HealthStaleDays is the age in days after which uncommitted changes are reported
+	HealthStaleDays int `mapstructure:"health_stale_days" json:"health_stale_days"`
+	// HealthStashDays is the age in days after which stashes are reported
+	HealthStashDays int `mapstructure:"health_stash_days" json:"health_stash_days"`
+	// HealthLargeFileMB is the size in megabytes above which untracked files are reported
+	HealthLargeFileMB int `mapstructure:"health_large_file_mb" json:"health_large_file_mb"`
+	// DiskScanIntervalHours is how long a disk usage measurement is reused before the project is measured again
+	DiskScanIntervalHours int `mapstructure:"disk_scan_interval_hours" json:"disk_scan_interval_hours"`
+	// HistoryMaxAgeDays is the age in days after which project open history is pruned, 0 keeps opens of any age
+	HistoryMaxAgeDays int `mapstructure:"history_max_age_days" json:"history_max_age_days"`
+	// HistoryMaxEntries is the number of opens kept per project
+	HistoryMaxEntries int `mapstructure:"history_max_entries" json:"history_max_entries"`
+	// Forges configures additional forges, or overrides the built in ones, for repository links and metadata
+	Forges []ForgeConfig `mapstructure:"forges" json:"forges"`
+	// ForgeCacheTTLMinutes is how long forge metadata is reused before it is fetched again
+	ForgeCacheTTLMinutes int `mapstructure:"forge_cache_ttl_minutes" json:"forge_cache_ttl_minutes"`
+	// SecretsBackend is where credentials are stored, either file or secret-service
+	SecretsBackend string `mapstructure:"secrets_backend" json:"secrets_backend"`
 }
@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// DiskUsage is the on disk size of a project broken down by kind, in bytes.
+type DiskUsage struct {
+	// Source is the size of files tracked by version control, or of all regular files when the project isn't a
+	// repository
+	Source int64 `json:"source"`
+	// Git is the size of the .git directory
+	Git int64 `json:"git"`
+	// Artifacts are the sizes of known build and cache directories keyed by their path relative to the project
+	Artifacts map[string]int64 `json:"artifacts,omitempty"`
+	// Other is the size of untracked files outside of artifact directories
+	Other int64 `json:"other"`
+	// Total is the size of the whole project directory
+	Total int64 `json:"total"`
+	// ScannedAt is when the project was last measured
+	ScannedAt time.Time `json:"scanned_at"`
+	// ModTime is the latest modification time of the project's directories when it was measured, used to skip
+	// unchanged projects on incremental scans
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ArtifactSize returns the combined size of all artifact directories.
+func (d DiskUsage) ArtifactSize() int64 {
+	var size int64
+
+	for _, s := range d.Artifacts {
+		size += s
+	}
+
+	return size
+}
+
+// CleanResult describes the build artifacts removed, or that would be removed on a dry run, from a project.
+type CleanResult struct {
+	// Path is the path from the root Project Directory
+	Path   string `json:"path"`
+	DryRun bool   `json:"dry_run"`
+	// Removed are the artifact directories, relative to the project, which were or would be removed
+	Removed map[string]int64 `json:"removed,omitempty"`
+	// Skipped are artifact directories left in place along with the reason why
+	Skipped map[string]string `json:"skipped,omitempty"`
+	// Freed is the number of bytes which were or would be freed
+	Freed int64 `json:"freed"`
+}
@@ -1,8 +1,72 @@
 package dto
 
+import "errors"
+
 type Editor struct {
 	Icon    string `json:"icon,omitempty"`
 	Path    string `json:"path"`
 	Name    string `json:"name"`
 	Default bool   `json:"default,omitempty"`
 }
+
+// Validate checks that the editor can be stored.
+func (e Editor) Validate() error {
+	if e.Name == "" {
+		return errors.New("editor name is required")
+	}
+
+	if e.Path == "" {
+		return errors.New("editor path is required")
+	}
+
+	return nil
+}
+
+// EditorRule maps projects to an editor. Every non-empty criterion must match for the rule to apply. When several
+// rules apply the most specific wins, see EditorRule.Specificity.
+type EditorRule struct {
+	// Name identifies the rule
+	Name string `json:"name" mapstructure:"name"`
+	// Editor is the name of the editor projects matching the rule are opened with
+	Editor string `json:"editor" mapstructure:"editor"`
+	// Language matches projects in which the language was detected
+	Language string `json:"language,omitempty" mapstructure:"language"`
+	// Tag matches projects carrying the tag
+	Tag string `json:"tag,omitempty" mapstructure:"tag"`
+	// Root matches projects located underneath the directory
+	Root string `json:"root,omitempty" mapstructure:"root"`
+	// Glob matches projects whose absolute path or path from the project directory matches the pattern
+	Glob string `json:"glob,omitempty" mapstructure:"glob"`
+}
+
+// weights of the rule criteria, each one outweighing all less specific criteria together
+const (
+	languageWeight = 1 << iota
+	tagWeight
+	rootWeight
+	globWeight
+)
+
+// Specificity ranks how narrowly a rule selects projects. A glob is more specific than a root, a root more than a tag
+// and a tag more than a language. Criteria add up, so a rule combining a root and a language beats a root alone.
+func (r EditorRule) Specificity() int {
+	score := 0
+
+	if r.Glob != "" {
+		score += globWeight
+	}
+
+	if r.Root != "" {
+		score += rootWeight
+	}
+
+	if r.Tag != "" {
+		score += tagWeight
+	}
+
+	if r.Language != "" {
+		score += languageWeight
+	}
+
+	return score
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// ForgeConfig configures access to a code forge. Entries for github.com, gitlab.com, gitea.com and codeberg.org are
+// built in and may be overridden by host.
+type ForgeConfig struct {
+	// Host is the hostname used in remote urls, e.g. github.com
+	Host string `json:"host" mapstructure:"host"`
+	// Kind is the forge software, one of github, gitlab or gitea
+	Kind string `json:"kind" mapstructure:"kind"`
+	// APIURL is the base url of the REST API. It defaults to the conventional location for Kind on Host.
+	APIURL string `json:"api_url,omitempty" mapstructure:"api_url"`
+	// Token authenticates API requests. It is looked up in the secret store, never read from the config file.
+	Token string `json:"-" mapstructure:"-"`
+}
+
+// RemoteMetadata is repository information fetched from a forge API.
+type RemoteMetadata struct {
+	// Forge is the kind of forge the metadata came from
+	Forge            string `json:"forge"`
+	Description      string `json:"description,omitempty"`
+	DefaultBranch    string `json:"default_branch,omitempty"`
+	OpenPullRequests int    `json:"open_pull_requests"`
+	Stars            int    `json:"stars"`
+	Archived         bool   `json:"archived"`
+	// FetchedAt is when the metadata was fetched from the forge
+	FetchedAt time.Time `json:"fetched_at"`
+	// Stale is true when the forge couldn't be reached and an expired cache entry was used instead
+	Stale bool `json:"stale,omitempty"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+// Group is a named, manually curated set of projects.
+type Group struct {
+	Name string `json:"name" mapstructure:"name"`
+	// Projects are the paths of the member projects from the root Project Directory
+	Projects []string `json:"projects" mapstructure:"projects"`
+}
+
+// SavedView is a named project search which is evaluated every time the view is opened.
+type SavedView struct {
+	Name     string          `json:"name" mapstructure:"name"`
+	Selector ProjectSelector `json:"selector" mapstructure:"selector"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// HealthIssueKind identifies the kind of hygiene problem found in a repository.
+type HealthIssueKind string
+
+const (
+	HealthStaleChanges   HealthIssueKind = "stale_changes"
+	HealthUnpushed       HealthIssueKind = "unpushed_commits"
+	HealthNoUpstream     HealthIssueKind = "no_upstream"
+	HealthDetachedHead   HealthIssueKind = "detached_head"
+	HealthNoRemotes      HealthIssueKind = "no_remotes"
+	HealthLargeUntracked HealthIssueKind = "large_untracked"
+	HealthStaleStash     HealthIssueKind = "stale_stash"
+)
+
+// HealthIssue is a single problem found in a repository.
+type HealthIssue struct {
+	Kind    HealthIssueKind `json:"kind"`
+	Message string          `json:"message"`
+	// Subjects are the branches, files or stashes the issue refers to
+	Subjects []string `json:"subjects,omitempty"`
+}
+
+// HealthReport is the result of a hygiene check of a single project.
+type HealthReport struct {
+	// Path is the path from the root Project Directory
+	Path string `json:"path"`
+	// Repository is false when the project isn't under version control and no checks were run
+	Repository bool          `json:"repository"`
+	Issues     []HealthIssue `json:"issues,omitempty"`
+	// Error is set when the checks could not be completed
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// OpenEvent records a project being opened.
+type OpenEvent struct {
+	// Path is the path from the root Project Directory
+	Path string `json:"path"`
+	// Editor is the name of the editor the project was opened with
+	Editor   string    `json:"editor"`
+	OpenedAt time.Time `json:"opened_at"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// Language is a language or toolchain detected in a project.
+type Language struct {
+	// Name is the lowercase name of the language or runtime, e.g. go, node, rust, python or java
+	Name string `json:"name" mapstructure:"name"`
+	// Version is the toolchain version declared by the project, if any
+	Version string `json:"version,omitempty" mapstructure:"version"`
+	// Manifest is the file the language was detected from
+	Manifest string `json:"manifest" mapstructure:"manifest"`
+	// Frameworks are well known frameworks the project depends on
+	Frameworks []string `json:"frameworks,omitempty" mapstructure:"frameworks"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// Links are web pages of a repository on its forge. Links which can't be derived are left blank.
+type Links struct {
+	Home         string `json:"home,omitempty" mapstructure:"home"`
+	PullRequests string `json:"pull_requests,omitempty" mapstructure:"pull_requests"`
+	Issues       string `json:"issues,omitempty" mapstructure:"issues"`
+	CI           string `json:"ci,omitempty" mapstructure:"ci"`
+	// Compare compares the checked out branch against the default branch
+	Compare string `json:"compare,omitempty" mapstructure:"compare"`
+	// File is the web view of a file on the checked out branch, with {file} standing in for the path of the file
+	File string `json:"file,omitempty" mapstructure:"file"`
+}
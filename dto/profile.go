@@ -0,0 +1,7 @@
+package dto
+
+// Profile is a named set of config and data, such as separate work and personal project directories.
+type Profile struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
@@ -8,8 +8,52 @@ type Project struct {
 	OpenWith string `json:"open_with" mapstructure:"open_with"`
 	// Hide means to intentionally hide the project on the main project list
 	Hide bool `json:"hide" mapstructure:"hide"`
+	// Hidden is derived when listing projects and is true when the project is hidden, either by Hide or by one of the
+	// configured hidden patterns. It is never stored.
+	Hidden bool `json:"hidden,omitempty" mapstructure:"-"`
+	// Pinned projects are listed first in the quick launcher
+	Pinned bool `json:"pinned,omitempty" mapstructure:"pinned"`
+	// Branch is the checked out git branch
+	Branch string `json:"branch,omitempty" mapstructure:"branch"`
+	// WorktreeOf is set when the project is a linked git worktree. It is the main worktree, as a project path when it
+	// is in the project directory and an absolute path otherwise.
+	WorktreeOf string `json:"worktree_of,omitempty" mapstructure:"worktree_of"`
+	// Parent is set on sub-projects, directories inside another project which are listed as projects of their own. It
+	// is the path of the project containing it.
+	Parent string `json:"parent,omitempty" mapstructure:"parent"`
 	// Remotes are the git remotes
 	Remotes []string `json:"remotes,omitempty" mapstructure:"remotes"`
 	// RepositoryURL is the url to the repository
 	RepositoryURLs []string `json:"repository_urls,omitempty" mapstructure:"repository_urls"`
+	// Tags are user defined labels used to group and select projects
+	Tags []string `json:"tags,omitempty" mapstructure:"tags"`
+	// AutoTags are tags derived from the project contents, such as detected languages. They are replaced on every scan.
+	AutoTags []string `json:"auto_tags,omitempty" mapstructure:"auto_tags"`
+	// Languages are the languages and toolchains detected in the project
+	Languages []Language `json:"languages,omitempty" mapstructure:"languages"`
+	// DiskUsage is the most recent size measurement of the project
+	DiskUsage *DiskUsage `json:"disk_usage,omitempty" mapstructure:"disk_usage"`
+	// Remote is metadata about the repository fetched from the forge hosting its first recognised remote
+	Remote *RemoteMetadata `json:"remote,omitempty" mapstructure:"remote"`
+	// Links are the web pages of the repository on its forge. Every link is blank when no forge was recognised.
+	Links *Links `json:"links,omitempty" mapstructure:"links"`
 }
+
+// AllTags returns the user defined tags followed by the automatic tags.
+func (p Project) AllTags() []string {
+	return append(append([]string(nil), p.Tags...), p.AutoTags...)
+}
+
+// ListOptions changes how the project list is returned.
+type ListOptions struct {
+	// Sort is the order of the list, one of SortName, SortFrecency or SortRecent. Blank keeps discovery order.
+	Sort string `json:"sort,omitempty" mapstructure:"sort"`
+	// IncludeHidden includes hidden projects in the list
+	IncludeHidden bool `json:"include_hidden,omitempty" mapstructure:"include_hidden"`
+}
+
+const (
+	SortName     = "name"
+	SortFrecency = "frecency"
+	SortRecent   = "recent"
+)
@@ -0,0 +1,12 @@
+package dto
+
+// ProjectSelector narrows the project list down to a subset of projects. Empty fields match everything, and all
+// non-empty fields must match for a project to be selected.
+type ProjectSelector struct {
+	// Tags selects projects carrying every one of the given tags
+	Tags []string `json:"tags,omitempty" mapstructure:"tags"`
+	// Root selects projects located underneath the given directory
+	Root string `json:"root,omitempty" mapstructure:"root"`
+	// Query selects projects whose path, tags or remotes contain the query, ignoring case
+	Query string `json:"query,omitempty" mapstructure:"query"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// SetupStep is a step of first run setup.
+type SetupStep string
+
+const (
+	// SetupProjectDirectory asks for the directory projects are discovered in
+	SetupProjectDirectory SetupStep = "project_directory"
+	// SetupEditors asks for the editors projects are opened with
+	SetupEditors SetupStep = "editors"
+	// SetupComplete means onboarding is finished
+	SetupComplete SetupStep = "complete"
+)
+
+// SetupState reports the progress of first run setup.
+type SetupState struct {
+	// Step is the next step to complete
+	Step     SetupStep `json:"step"`
+	Complete bool      `json:"complete"`
+	// Degraded is set when projects couldn't be loaded, Reason says why
+	Degraded bool   `json:"degraded"`
+	Reason   string `json:"reason,omitempty"`
+	// Candidates are existing directories which commonly hold projects
+	Candidates []string `json:"candidates"`
+	// Editors are the installed editors that were detected
+	Editors []Editor `json:"editors"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// Submodule is a git submodule of a project.
+type Submodule struct {
+	// Name identifies the submodule in .gitmodules
+	Name string `json:"name"`
+	// Path is where the submodule is checked out, relative to the project
+	Path string `json:"path"`
+	// URL is the repository of the submodule
+	URL string `json:"url"`
+	// Branch is the branch the submodule follows, blank for the remote's default branch
+	Branch string `json:"branch,omitempty"`
+	// Pinned is the commit the project records for the submodule
+	Pinned string `json:"pinned"`
+	// Current is the commit checked out, blank when the submodule isn't initialized
+	Current string `json:"current,omitempty"`
+	// RemoteHead is the commit the followed branch points at on the remote, blank until it is looked up
+	RemoteHead string `json:"remote_head,omitempty"`
+	// RemoteError is why the remote head couldn't be looked up
+	RemoteError string `json:"remote_error,omitempty"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+// FieldError describes why the value of a config key is invalid.
+type FieldError struct {
+	// Field is the config key, with an index and sub key for values inside lists, e.g. forges[0].kind
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Failure describes a failed operation so the frontend can render it.
+type Failure struct {
+	// Code classifies the error, e.g. invalid, not_found or locked
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields lists the invalid keys when a config update was rejected
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// EventError is the reply to a request event which failed and isn't answered with a result.
+type EventError struct {
+	// Event is the name of the request event
+	Event   string `json:"event"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// EventResult is the reply to a request event which is answered with a result, correlated by the id the frontend
+// sent with the request.
+type EventResult struct {
+	ID string `json:"id"`
+	// Error is nil when the request succeeded
+	Error *Failure `json:"error,omitempty"`
+}
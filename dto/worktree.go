@@ -0,0 +1,31 @@
+package dto
+
+// Worktree is a working tree of a git repository. Besides its main worktree a repository can have linked worktrees,
+// each with another branch checked out.
+type Worktree struct {
+	// Path is the absolute path of the worktree
+	Path string `json:"path"`
+	// Project is the project path of the worktree, blank when it is outside of the project directory
+	Project string `json:"project,omitempty"`
+	// Branch is the checked out branch, blank when HEAD is detached
+	Branch string `json:"branch,omitempty"`
+	// Head is the abbreviated commit checked out when HEAD is detached
+	Head string `json:"head,omitempty"`
+	// Main is set for the main worktree, the one containing the repository
+	Main bool `json:"main,omitempty"`
+	// Locked worktrees are protected from being pruned
+	Locked bool `json:"locked,omitempty"`
+	// Prunable worktrees no longer exist on disk
+	Prunable bool `json:"prunable,omitempty"`
+}
+
+// WorktreeRequest asks for a new linked worktree.
+type WorktreeRequest struct {
+	// Branch is checked out in the worktree
+	Branch string `json:"branch" mapstructure:"branch"`
+	// Create starts Branch from the current HEAD instead of checking out an existing branch
+	Create bool `json:"create,omitempty" mapstructure:"create"`
+	// Dir is where the worktree is created, relative to the project directory unless absolute. Blank creates it in the
+	// project directory, named after the project and the branch.
+	Dir string `json:"dir,omitempty" mapstructure:"dir"`
+}
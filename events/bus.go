@@ -0,0 +1,216 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+var (
+	ErrUnknownEvent   = errors.New("event is not registered")
+	ErrMissingPayload = errors.New("event payload is missing")
+	ErrPayloadType    = errors.New("event payload has the wrong type")
+)
+
+// Validator is implemented by request payloads which check themselves after decoding.
+type Validator interface {
+	Validate() error
+}
+
+// RequestHandler handles a decoded and validated request payload. payload is a value of the type registered for the
+// event, or nil for events without a payload.
+type RequestHandler func(payload interface{}) error
+
+// NotificationHandler handles a notification whose arguments were checked against the registry.
+type NotificationHandler func(args ...interface{})
+
+// Bus exchanges registered events over the events of the platform. A nil Bus drops everything, so services can emit
+// before they were initialised.
+type Bus struct {
+	emitter platform.Events
+	log     platform.Logger
+}
+
+func NewBus(emitter platform.Events, log platform.Logger) *Bus {
+	return &Bus{emitter: emitter, log: log}
+}
+
+// Handle registers handler for the request event name. Payloads which fail to decode or validate, and handler errors,
+// are logged. Result requests are always answered with their result notification, other requests only when they
+// fail, with a RequestFailed event.
+func (b *Bus) Handle(name string, handler RequestHandler) {
+	if b == nil {
+		return
+	}
+
+	event, ok := Lookup(name)
+	if !ok || event.Direction != Request {
+		b.log.ErrorFields("Handler registered for an unknown request event", platform.Fields{"event": name})
+
+		return
+	}
+
+	b.emitter.On(name, func(optionalData ...interface{}) {
+		payload, err := decode(event, optionalData)
+		if err != nil {
+			err = failure.New(failure.Invalid, err)
+		} else {
+			err = handler(payload)
+		}
+
+		if event.Result {
+			b.reply(event, optionalData, err)
+
+			return
+		}
+
+		if err != nil {
+			b.fail(name, err)
+		}
+	})
+}
+
+// On registers handler for the notification event name. Notifications whose arguments don't match the registry are
+// logged and dropped.
+func (b *Bus) On(name string, handler NotificationHandler) {
+	if b == nil {
+		return
+	}
+
+	event, ok := Lookup(name)
+	if !ok || event.Direction != Notification {
+		b.log.ErrorFields("Listener registered for an unknown notification event", platform.Fields{"event": name})
+
+		return
+	}
+
+	b.emitter.On(name, func(optionalData ...interface{}) {
+		err := check(event, optionalData)
+		if err != nil {
+			b.log.ErrorFields("Dropping malformed notification", platform.Fields{"event": name, "error": err})
+
+			return
+		}
+
+		handler(optionalData...)
+	})
+}
+
+// Emit sends the notification event name with args. Unregistered events and arguments not matching the registry are
+// logged and not sent.
+func (b *Bus) Emit(name string, args ...interface{}) {
+	if b == nil {
+		return
+	}
+
+	event, ok := Lookup(name)
+	if !ok || event.Direction != Notification {
+		b.log.ErrorFields("Not emitting unknown notification event", platform.Fields{"event": name})
+
+		return
+	}
+
+	err := check(event, args)
+	if err != nil {
+		b.log.ErrorFields("Not emitting malformed notification", platform.Fields{"event": name, "error": err})
+
+		return
+	}
+
+	b.emitter.Emit(name, args...)
+}
+
+// fail logs a failed request and replies with a RequestFailed event.
+func (b *Bus) fail(name string, err error) {
+	b.log.ErrorFields("Request event failed", platform.Fields{"event": name, "error": err})
+
+	description := failure.Describe(err)
+
+	b.emitter.Emit(RequestFailed, dto.EventError{Event: name, Code: description.Code, Message: description.Message})
+}
+
+// reply answers a result request with the id sent after its payload. Requests sent without an id are still answered,
+// so listeners which don't correlate learn about failures too.
+func (b *Bus) reply(event Event, data []interface{}, err error) {
+	result := dto.EventResult{}
+
+	if len(data) > len(event.Args) {
+		result.ID, _ = data[len(event.Args)].(string)
+	}
+
+	if err != nil {
+		b.log.ErrorFields("Request event failed", platform.Fields{"event": event.Name, "id": result.ID, "error": err})
+
+		description := failure.Describe(err)
+		result.Error = &description
+	}
+
+	b.Emit(ResultName(event.Name), result)
+}
+
+// decode converts the payload of a request into a value of the registered type and validates it. Blank payloads are
+// rejected, requests which need no payload decode to nil.
+func decode(event Event, data []interface{}) (interface{}, error) {
+	if len(event.Args) == 0 {
+		return nil, nil
+	}
+
+	if len(data) == 0 || data[0] == nil {
+		return nil, ErrMissingPayload
+	}
+
+	typ := reflect.TypeOf(event.Args[0].Type)
+	value := reflect.New(typ)
+
+	err := mapstructure.Decode(data[0], value.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPayloadType, err)
+	}
+
+	if value.Elem().IsZero() {
+		return nil, ErrMissingPayload
+	}
+
+	payload := value.Elem().Interface()
+
+	if v, ok := payload.(Validator); ok {
+		err = v.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return payload, nil
+}
+
+// check verifies that args match the registered arguments of event. nil stands for an absent slice or map, so handlers
+// may type assert any other argument without checking.
+func check(event Event, args []interface{}) error {
+	if len(args) != len(event.Args) {
+		return fmt.Errorf("%w: expected %d arguments, got %d", ErrPayloadType, len(event.Args), len(args))
+	}
+
+	for i, arg := range args {
+		want := reflect.TypeOf(event.Args[i].Type)
+
+		if arg == nil {
+			if want.Kind() != reflect.Slice && want.Kind() != reflect.Map {
+				return fmt.Errorf("%w: %s is nil", ErrPayloadType, event.Args[i].Name)
+			}
+
+			continue
+		}
+
+		if got := reflect.TypeOf(arg); got != want {
+			return fmt.Errorf("%w: %s is %s, expected %s", ErrPayloadType, event.Args[i].Name, got, want)
+		}
+	}
+
+	return nil
+}
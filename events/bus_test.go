@@ -0,0 +1,184 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/failure"
+	"github.com/mattouille/proman/platform/fake"
+	"github.com/mattouille/proman/service/database"
+)
+
+func newTestBus() (*Bus, *fake.Platform) {
+	platform := fake.New()
+	runtime := platform.Runtime()
+
+	return NewBus(runtime.Events, runtime.Log("events")), platform
+}
+
+// lastResult returns the last result notification of the request event name.
+func lastResult(t *testing.T, platform *fake.Platform, name string) dto.EventResult {
+	t.Helper()
+
+	emitted := platform.Events.Emitted(ResultName(name))
+	if len(emitted) == 0 {
+		t.Fatalf("%s was not answered", name)
+	}
+
+	return emitted[len(emitted)-1][0].(dto.EventResult)
+}
+
+func TestHandleResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []interface{}
+		handler error
+		id      string
+		code    string
+	}{
+		{name: "success", data: []interface{}{"app", "1"}, id: "1"},
+		{name: "without id", data: []interface{}{"app"}},
+		{name: "handler error", data: []interface{}{"app", "2"}, handler: errors.New("boom"), id: "2",
+			code: failure.Internal},
+		{name: "known handler error", data: []interface{}{"app", "3"},
+			handler: fmt.Errorf("opening app: %w", database.ErrNoRecords), id: "3", code: failure.NotFound},
+		{name: "missing payload", data: []interface{}{nil, "4"}, id: "4", code: failure.Invalid},
+		{name: "wrong payload type", data: []interface{}{[]string{"app"}, "5"}, id: "5", code: failure.Invalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus, platform := newTestBus()
+
+			var handled interface{}
+
+			bus.Handle(OpenProject, func(payload interface{}) error {
+				handled = payload
+
+				return tt.handler
+			})
+
+			platform.Events.Emit(OpenProject, tt.data...)
+
+			result := lastResult(t, platform, OpenProject)
+			if result.ID != tt.id {
+				t.Errorf("answered with id %q, want %q", result.ID, tt.id)
+			}
+
+			if tt.code == "" {
+				if result.Error != nil {
+					t.Errorf("unexpected error %+v", result.Error)
+				}
+
+				if handled != "app" {
+					t.Errorf("handled %v, want the decoded payload", handled)
+				}
+
+				return
+			}
+
+			if result.Error == nil || result.Error.Code != tt.code {
+				t.Errorf("answered with error %+v, want code %s", result.Error, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandleValidates(t *testing.T) {
+	bus, platform := newTestBus()
+
+	called := false
+
+	bus.Handle(ActionRun, func(payload interface{}) error {
+		called = true
+
+		return nil
+	})
+
+	platform.Events.Emit(ActionRun, map[string]interface{}{"path": "app"}, "7")
+
+	result := lastResult(t, platform, ActionRun)
+	if result.ID != "7" || result.Error == nil || result.Error.Code != failure.Invalid {
+		t.Errorf("answered with %+v, want an invalid error for id 7", result)
+	}
+
+	if called {
+		t.Errorf("handler called with an invalid payload")
+	}
+
+	platform.Events.Emit(ActionRun, map[string]interface{}{"path": "app", "action": "lazygit"}, "8")
+
+	result = lastResult(t, platform, ActionRun)
+	if result.ID != "8" || result.Error != nil || !called {
+		t.Errorf("answered with %+v, want the valid payload handled", result)
+	}
+}
+
+func TestHandleFailure(t *testing.T) {
+	bus, platform := newTestBus()
+
+	bus.Handle(OpenURL, func(payload interface{}) error {
+		return failure.New(failure.Unavailable, errors.New("no browser"))
+	})
+
+	platform.Events.Emit(OpenURL, "https://example.com")
+
+	failed := platform.Events.Emitted(RequestFailed)
+	if len(failed) != 1 {
+		t.Fatalf("%d %s events, want 1", len(failed), RequestFailed)
+	}
+
+	want := dto.EventError{Event: OpenURL, Code: failure.Unavailable, Message: "no browser"}
+	if got := failed[0][0].(dto.EventError); got != want {
+		t.Errorf("failed with %+v, want %+v", got, want)
+	}
+
+	if n := len(platform.Events.Emitted(ResultName(OpenURL))); n != 0 {
+		t.Errorf("%d results for a request without result", n)
+	}
+}
+
+func TestEmitChecksArguments(t *testing.T) {
+	bus, platform := newTestBus()
+
+	bus.Emit(ProjectsRemote, "app")
+	bus.Emit(ProjectsRemote, "app", dto.DiskUsage{})
+	bus.Emit("projects.unknown")
+	bus.Emit(ProjectsRemote, "app", dto.RemoteMetadata{})
+
+	if n := len(platform.Events.Emitted(ProjectsRemote)); n != 1 {
+		t.Errorf("%d %s events, want only the well formed one", n, ProjectsRemote)
+	}
+
+	if n := len(platform.Events.Emitted("projects.unknown")); n != 0 {
+		t.Errorf("an unregistered event was emitted")
+	}
+}
+
+func TestOnDropsMalformed(t *testing.T) {
+	bus, platform := newTestBus()
+
+	var received []string
+
+	bus.On(ConfigSetProjectDirectory, func(args ...interface{}) {
+		received = append(received, args[0].(string))
+	})
+
+	platform.Events.Emit(ConfigSetProjectDirectory, "/src")
+	platform.Events.Emit(ConfigSetProjectDirectory, 1, "")
+	platform.Events.Emit(ConfigSetProjectDirectory, "/work", "")
+
+	if len(received) != 1 || received[0] != "/work" {
+		t.Errorf("received %v, want only the well formed notification", received)
+	}
+}
+
+func TestNilBus(t *testing.T) {
+	var bus *Bus
+
+	bus.Handle(OpenURL, func(interface{}) error { return nil })
+	bus.On(ProjectsChanged, func(...interface{}) {})
+	bus.Emit(ProjectsChanged)
+}
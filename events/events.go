@@ -0,0 +1,152 @@
+// Package events is the contract for the events exchanged between the Go services and the frontend. Every event is
+// registered with the payload it carries. Requests sent by the frontend are decoded and validated before they reach a
+// handler. Requests which change something are answered with a correlated <name>.result event, other failures with a
+// RequestFailed event, instead of panicking the backend. The frontend module frontend/src/events.js is generated from
+// the registry, see the gen directory.
+package events
+
+//go:generate go run ./gen -out ../frontend/src/events.js
+
+import (
+	"github.com/mattouille/proman/dto"
+)
+
+// Direction is the way an event travels.
+type Direction string
+
+const (
+	// Request events are sent by the frontend and handled by the Go services
+	Request Direction = "request"
+	// Notification events are emitted by the Go services, for the frontend and for other services
+	Notification Direction = "notification"
+)
+
+// Names of the registered events.
+const (
+	OpenURL                      = "OpenURL"
+	OpenProject                  = "OpenProject"
+	ProjectsBatch                = "projects.batch"
+	ConfigUpdate                 = "config.update"
+	ConfigSelectProjectDirectory = "config.select_project_directory"
+	ValidateConfig               = "validate.config"
+	EditorUpsert                 = "editor.upsert"
+	EditorRemove                 = "editor.remove"
+	ActionRun                    = "action.run"
+
+	RequestFailed             = "request.failed"
+	ConfigChanged             = "config.changed"
+	ConfigInvalid             = "config.invalid"
+	ConfigSetProjectDirectory = "config.set_project_directory"
+	ValidateConfigCompleted   = "validate.config.completed"
+	GroupsChanged             = "groups.changed"
+	InstanceFocus             = "instance.focus"
+	LauncherShow              = "launcher.show"
+	ProfilesChanged           = "profiles.changed"
+	ProjectsChanged           = "projects.changed"
+	ProjectsBatchProgress     = "projects.batch.progress"
+	ProjectsBatchCompleted    = "projects.batch.completed"
+	ProjectsDiskUsage         = "projects.disk_usage"
+	ProjectsRemote            = "projects.remote"
+	SecretsChanged            = "secrets.changed"
+	SetupChanged              = "setup.changed"
+)
+
+// Arg is an argument of an event payload.
+type Arg struct {
+	Name string
+	// Type is a zero value of the argument's type
+	Type interface{}
+}
+
+// Event describes a registered event.
+type Event struct {
+	Name      string
+	Direction Direction
+	// Args are the payload arguments in order. Requests carry at most one.
+	Args []Arg
+	Doc  string
+	// Result requests take a request id after their payload and are answered with a dto.EventResult carrying it in a
+	// notification named by ResultName.
+	Result bool
+}
+
+// Registry lists every event. Emitting or handling an event missing from it is an error.
+var Registry = []Event{
+	{Name: OpenURL, Direction: Request, Args: []Arg{{"url", ""}}, Doc: "Opens a url in the browser"},
+	{Name: OpenProject, Direction: Request, Args: []Arg{{"path", ""}}, Doc: "Opens a project with its editor",
+		Result: true},
+	{Name: ProjectsBatch, Direction: Request, Args: []Arg{{"request", dto.BatchRequest{}}},
+		Doc: "Starts a git operation across a selection of projects, answered once it was accepted", Result: true},
+	{Name: ConfigUpdate, Direction: Request, Args: []Arg{{"config", map[string]interface{}{}}},
+		Doc: "Validates and writes config keys", Result: true},
+	{Name: ConfigSelectProjectDirectory, Direction: Request, Doc: "Asks the user to pick a project directory",
+		Result: true},
+	{Name: ValidateConfig, Direction: Request, Args: []Arg{{"config", map[string]interface{}{}}},
+		Doc: "Validates config keys, answered with validate.config.completed"},
+	{Name: EditorUpsert, Direction: Request, Args: []Arg{{"editor", dto.Editor{}}}, Doc: "Creates or updates an editor",
+		Result: true},
+	{Name: EditorRemove, Direction: Request, Args: []Arg{{"name", ""}}, Doc: "Removes an editor by name",
+		Result: true},
+	{Name: ActionRun, Direction: Request, Args: []Arg{{"run", dto.ActionRun{}}},
+		Doc: "Runs a custom action against a project", Result: true},
+
+	{Name: RequestFailed, Direction: Notification, Args: []Arg{{"error", dto.EventError{}}},
+		Doc: "A request event failed to decode, validate or run"},
+	{Name: ConfigChanged, Direction: Notification,
+		Args: []Arg{{"current", dto.ConfigSchema{}}, {"previous", dto.ConfigSchema{}}},
+		Doc:  "The config file was edited by hand"},
+	{Name: ConfigInvalid, Direction: Notification, Args: []Arg{{"message", ""}, {"fields", []dto.FieldError{}}},
+		Doc: "A hand edit of the config file was rejected"},
+	{Name: ConfigSetProjectDirectory, Direction: Notification, Args: []Arg{{"directory", ""}, {"error", ""}},
+		Doc: "A project directory was picked"},
+	{Name: ValidateConfigCompleted, Direction: Notification, Args: []Arg{{"errors", []dto.FieldError{}}},
+		Doc: "The result of validate.config"},
+	{Name: GroupsChanged, Direction: Notification, Doc: "Groups or saved views changed"},
+	{Name: InstanceFocus, Direction: Notification, Doc: "Another launch of proman was forwarded to this one"},
+	{Name: LauncherShow, Direction: Notification, Doc: "Another launch of proman asked for the quick launcher"},
+	{Name: ProfilesChanged, Direction: Notification, Doc: "Profiles were created, deleted or switched"},
+	{Name: ProjectsChanged, Direction: Notification, Doc: "The listed projects changed and should be refetched"},
+	{Name: ProjectsBatchProgress, Direction: Notification, Args: []Arg{{"progress", dto.BatchProgress{}}},
+		Doc: "A project in a batch finished"},
+	{Name: ProjectsBatchCompleted, Direction: Notification, Args: []Arg{{"results", []dto.BatchResult{}}},
+		Doc: "A batch finished, with the result of every project"},
+	{Name: ProjectsDiskUsage, Direction: Notification, Args: []Arg{{"path", ""}, {"usage", dto.DiskUsage{}}},
+		Doc: "The disk usage of a project was measured"},
+	{Name: ProjectsRemote, Direction: Notification, Args: []Arg{{"path", ""}, {"remote", dto.RemoteMetadata{}}},
+		Doc: "The forge metadata of a project was fetched"},
+	{Name: SecretsChanged, Direction: Notification, Doc: "Secrets were stored, deleted or unlocked"},
+	{Name: SetupChanged, Direction: Notification, Args: []Arg{{"state", dto.SetupState{}}},
+		Doc: "First run setup progressed"},
+}
+
+// registers the result notifications of requests which are answered with one
+func init() {
+	for _, event := range Registry {
+		if !event.Result {
+			continue
+		}
+
+		Registry = append(Registry, Event{
+			Name:      ResultName(event.Name),
+			Direction: Notification,
+			Args:      []Arg{{"result", dto.EventResult{}}},
+			Doc:       "The result of " + event.Name,
+		})
+	}
+}
+
+// ResultName returns the name of the notification answering the request event name.
+func ResultName(name string) string {
+	return name + ".result"
+}
+
+// Lookup returns the registered event called name.
+func Lookup(name string) (Event, bool) {
+	for _, event := range Registry {
+		if event.Name == name {
+			return event, true
+		}
+	}
+
+	return Event{}, false
+}
@@ -0,0 +1,261 @@
+// Command gen writes the frontend events module from the event registry. Run it with go generate ./events.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattouille/proman/events"
+)
+
+func main() {
+	out := flag.String("out", "frontend/src/events.js", "file to write the module to")
+	flag.Parse()
+
+	err := ioutil.WriteFile(*out, generate(), 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generator collects the JSDoc typedefs of the struct types used by event payloads.
+type generator struct {
+	typedefs map[string]string
+}
+
+func generate() []byte {
+	g := &generator{typedefs: make(map[string]string)}
+	body := new(bytes.Buffer)
+
+	for _, event := range events.Registry {
+		name := identifier(event.Name)
+
+		var params, args []string
+
+		for _, arg := range event.Args {
+			params = append(params, fmt.Sprintf(" * @param {%s} %s", g.jsType(reflect.TypeOf(arg.Type)), arg.Name))
+			args = append(args, arg.Name)
+		}
+
+		fmt.Fprintf(body, "/** %s */\nexport const %s = %q;\n\n", event.Doc, name, event.Name)
+
+		switch event.Direction {
+		case events.Request:
+			fmt.Fprintf(body, "/**\n * Emits %s. %s.\n", event.Name, event.Doc)
+			writeLines(body, params)
+			fmt.Fprintf(body, " */\nexport const emit%s = (%s) => window.wails.Events.Emit(%s%s);\n\n",
+				name, strings.Join(args, ", "), name, prefixed(args))
+
+			if event.Result {
+				fmt.Fprintf(body, "/**\n * Sends %s and waits for its result. Rejects with the Failure of the request.\n",
+					event.Name)
+				writeLines(body, params)
+				fmt.Fprintf(body, " * @returns {Promise<void>}\n */\nexport const request%s = (%s) => request(%s%s);\n\n",
+					name, strings.Join(args, ", "), name, prefixed(args))
+			}
+		case events.Notification:
+			fmt.Fprintf(body, "/**\n * Listens for %s. %s.\n * @param {function(%s): void} callback\n */\n",
+				event.Name, event.Doc, strings.Join(types(params), ", "))
+			fmt.Fprintf(body, "export const on%s = (callback) => window.wails.Events.On(%s, callback);\n\n", name, name)
+		}
+	}
+
+	out := new(bytes.Buffer)
+	out.WriteString("// Code generated by go generate ./events. DO NOT EDIT.\n\n")
+
+	names := make([]string, 0, len(g.typedefs))
+	for name := range g.typedefs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		out.WriteString(g.typedefs[name])
+	}
+
+	out.WriteString(helpers)
+	out.Write(bytes.TrimRight(body.Bytes(), "\n"))
+	out.WriteString("\n")
+
+	return out.Bytes()
+}
+
+// helpers correlate result requests with their replies and parse the errors bound methods reject with.
+const helpers = `const pending = {};
+const listening = {};
+let lastRequestID = 0;
+
+const settle = (result) => {
+	const request = pending[result.id];
+	if (!request) {
+		return;
+	}
+
+	delete pending[result.id];
+
+	if (result.error) {
+		request.reject(result.error);
+	} else {
+		request.resolve();
+	}
+};
+
+const request = (name, ...args) => {
+	if (!listening[name]) {
+		listening[name] = true;
+		window.wails.Events.On(name + ".result", settle);
+	}
+
+	const id = String(++lastRequestID);
+
+	return new Promise((resolve, reject) => {
+		pending[id] = { resolve, reject };
+		window.wails.Events.Emit(name, ...args, id);
+	});
+};
+
+/**
+ * Parses the error a bound method rejected with. Errors which aren't a Failure are described as internal.
+ * @param {*} error
+ * @returns {Failure}
+ */
+export const parseError = (error) => {
+	try {
+		const failure = JSON.parse(error);
+		if (failure && failure.code) {
+			return failure;
+		}
+	} catch (_) {
+		// not a Failure
+	}
+
+	return { code: "internal", message: String(error) };
+};
+
+`
+
+func writeLines(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+}
+
+// types extracts the types from @param lines.
+func types(params []string) []string {
+	list := make([]string, len(params))
+
+	for i, param := range params {
+		list[i] = param[strings.Index(param, "{")+1 : strings.LastIndex(param, "}")]
+	}
+
+	return list
+}
+
+func prefixed(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(args, ", ")
+}
+
+// identifier converts an event name such as projects.batch.progress into ProjectsBatchProgress.
+func identifier(name string) string {
+	var b strings.Builder
+
+	upper := true
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsType returns the JSDoc type of t, recording a typedef for structs.
+func (g *generator) jsType(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return "string"
+	case t.Kind() == reflect.Ptr:
+		return "?" + g.jsType(t.Elem())
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "Array<" + g.jsType(t.Elem()) + ">"
+	case reflect.Map:
+		return "Object<string, " + g.jsType(t.Elem()) + ">"
+	case reflect.Struct:
+		g.typedef(t)
+
+		return t.Name()
+	default:
+		return "*"
+	}
+}
+
+func (g *generator) typedef(t reflect.Type) {
+	if _, ok := g.typedefs[t.Name()]; ok {
+		return
+	}
+
+	// reserve the name first so recursive types terminate
+	g.typedefs[t.Name()] = ""
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "/**\n * @typedef {Object} %s\n", t.Name())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.Contains(tag, ",omitempty") {
+			name = "[" + name + "]"
+		}
+
+		fmt.Fprintf(buf, " * @property {%s} %s\n", g.jsType(field.Type), name)
+	}
+
+	buf.WriteString(" */\n\n")
+
+	g.typedefs[t.Name()] = buf.String()
+}
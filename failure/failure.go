@@ -0,0 +1,142 @@
+// Package failure describes errors for the frontend. Wails hands the frontend nothing but the message of an error
+// returned by a bound method, so bound methods return an *Error, whose message is its JSON encoded description, and
+// request events reply with the description itself.
+package failure
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/mattouille/proman/disk"
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/forge"
+	"github.com/mattouille/proman/path"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/service/secrets"
+	"github.com/mattouille/proman/vcs"
+)
+
+// Codes classify errors so the frontend can react to them without matching messages.
+const (
+	// Invalid errors are caused by the request, such as a config value out of range
+	Invalid = "invalid"
+	// NotFound errors name something which doesn't exist
+	NotFound = "not_found"
+	// Conflict errors clash with the current state, such as creating a profile which exists
+	Conflict = "conflict"
+	// Locked errors are resolved by unlocking something or closing another proman
+	Locked = "locked"
+	// Unavailable errors need a feature missing on this machine
+	Unavailable = "unavailable"
+	// Internal errors are everything else
+	Internal = "internal"
+)
+
+type code struct {
+	err  error
+	code string
+}
+
+// codes classifies known errors. Errors wrapping none of them are Internal.
+var codes = []code{
+	{database.ErrNoRecords, NotFound},
+	{database.ErrLocked, Locked},
+	{config.ErrInvalidProfile, Invalid},
+	{config.ErrProfileExists, Conflict},
+	{config.ErrProfileNotFound, NotFound},
+	{config.ErrProfileInUse, Conflict},
+	{secrets.ErrNotFound, NotFound},
+	{secrets.ErrLocked, Locked},
+	{secrets.ErrBadPassphrase, Invalid},
+	{secrets.ErrUnsupported, Unavailable},
+	{secrets.ErrUnknownBackend, Invalid},
+	{vcs.ErrUnknownOperation, Invalid},
+	{vcs.ErrBranchRequired, Invalid},
+	{vcs.ErrNotRepository, Invalid},
+	{vcs.ErrNoGitCommand, Unavailable},
+	{vcs.ErrNotWorktree, NotFound},
+	{vcs.ErrMainWorktree, Conflict},
+	{vcs.ErrWorktreeExists, Conflict},
+	{vcs.ErrWorktreeDirty, Conflict},
+	{vcs.ErrInvalidBranch, Invalid},
+	{vcs.ErrRelativeURL, Unavailable},
+	{vcs.ErrNoRemoteHead, NotFound},
+	{forge.ErrUnknownKind, Invalid},
+	{forge.ErrNotFound, NotFound},
+	{disk.ErrOutsideProject, Invalid},
+	{disk.ErrNoRepository, Invalid},
+	{path.ErrTargetBlank, Invalid},
+}
+
+// Register classifies err, and every error wrapping it, with code. It is meant for packages which can't be imported
+// here and must be called from an init function.
+func Register(err error, c string) {
+	codes = append(codes, code{err, c})
+}
+
+// Error is an error described for the frontend. errors.Is and errors.As see the error it describes.
+type Error struct {
+	Description dto.Failure
+	err         error
+}
+
+// New describes err with code.
+func New(c string, err error) *Error {
+	e := &Error{Description: dto.Failure{Code: c, Message: err.Error()}, err: err}
+
+	var invalid config.ValidationError
+	if errors.As(err, &invalid) {
+		e.Description.Fields = invalid
+	}
+
+	return e
+}
+
+// From describes err, which must not be nil, classifying it by the errors it wraps. Errors which were already
+// described are returned as they are.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var invalid config.ValidationError
+	if errors.As(err, &invalid) {
+		return New(Invalid, err)
+	}
+
+	for _, known := range codes {
+		if errors.Is(err, known.err) {
+			return New(known.code, err)
+		}
+	}
+
+	return New(Internal, err)
+}
+
+// Describe returns the description of err, which must not be nil.
+func Describe(err error) dto.Failure {
+	return From(err).Description
+}
+
+// Wrap replaces the error errp points to with its description. Bound methods defer it on their named error result.
+func Wrap(errp *error) {
+	if *errp != nil {
+		*errp = From(*errp)
+	}
+}
+
+// Error returns the JSON encoded description, which the frontend parses from the rejected promise.
+func (e *Error) Error() string {
+	out, err := json.Marshal(e.Description)
+	if err != nil {
+		return e.Description.Message
+	}
+
+	return string(out)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,110 @@
+package failure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/service/config"
+	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/vcs"
+)
+
+var errRegistered = errors.New("registered")
+
+func init() {
+	Register(errRegistered, Locked)
+}
+
+func TestFrom(t *testing.T) {
+	invalid := config.ValidationError{{Field: "disk_scan_interval_hours", Message: "must be at least 1"}}
+
+	tests := []struct {
+		name   string
+		err    error
+		code   string
+		fields []dto.FieldError
+	}{
+		{name: "known", err: database.ErrNoRecords, code: NotFound},
+		{name: "wrapped known", err: fmt.Errorf("reading projects: %w", database.ErrLocked), code: Locked},
+		{name: "twice wrapped known", err: fmt.Errorf("a: %w", fmt.Errorf("b: %w", vcs.ErrWorktreeDirty)),
+			code: Conflict},
+		{name: "registered", err: fmt.Errorf("x: %w", errRegistered), code: Locked},
+		{name: "validation error", err: invalid, code: Invalid, fields: invalid},
+		{name: "wrapped validation error", err: fmt.Errorf("update: %w", invalid), code: Invalid, fields: invalid},
+		{name: "unknown", err: errors.New("boom"), code: Internal},
+		{name: "already described", err: New(Unavailable, database.ErrNoRecords), code: Unavailable},
+		{name: "wrapped description", err: fmt.Errorf("x: %w", New(Conflict, errors.New("y"))), code: Conflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+
+			if e.Description.Code != tt.code {
+				t.Errorf("code %s, want %s", e.Description.Code, tt.code)
+			}
+
+			if !reflect.DeepEqual(e.Description.Fields, tt.fields) {
+				t.Errorf("fields %v, want %v", e.Description.Fields, tt.fields)
+			}
+
+			// errors which were described already are returned as they are
+			var described *Error
+			if !errors.As(tt.err, &described) && !reflect.DeepEqual(e.Unwrap(), tt.err) {
+				t.Errorf("description doesn't wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	invalid := config.ValidationError{{Field: "forge_cache_ttl_minutes", Message: "must be at most 10080"}}
+
+	tests := []error{
+		New(NotFound, errors.New(`project "app" not found`)),
+		From(fmt.Errorf("update: %w", invalid)),
+		From(errors.New("line one\nline two")),
+	}
+
+	for _, e := range tests {
+		t.Run(e.Error(), func(t *testing.T) {
+			var decoded dto.Failure
+
+			err := json.Unmarshal([]byte(e.Error()), &decoded)
+			if err != nil {
+				t.Fatalf("Error() isn't JSON: %s", err)
+			}
+
+			if want := Describe(e); !reflect.DeepEqual(decoded, want) {
+				t.Errorf("decoded %+v, want %+v", decoded, want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	wrapped := func(err error) (out error) {
+		defer Wrap(&out)
+
+		return err
+	}
+
+	if err := wrapped(nil); err != nil {
+		t.Errorf("nil was wrapped into %v", err)
+	}
+
+	err := wrapped(database.ErrNoRecords)
+
+	var e *Error
+	if !errors.As(err, &e) || e.Description.Code != NotFound {
+		t.Errorf("wrapped into %v, want a described not found error", err)
+	}
+
+	if !errors.Is(err, database.ErrNoRecords) {
+		t.Errorf("wrapped error doesn't unwrap to %v", database.ErrNoRecords)
+	}
+}
@@ -0,0 +1,184 @@
+// Package forge parses remote urls into forge repositories and fetches repository metadata from forge REST APIs.
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+)
+
+const (
+	KindGitHub = "github"
+	KindGitLab = "gitlab"
+	KindGitea  = "gitea"
+)
+
+// DefaultTimeout bounds every request made to a forge.
+const DefaultTimeout = 10 * time.Second
+
+var (
+	ErrUnknownKind = errors.New("unknown forge kind")
+	ErrNotFound    = errors.New("repository not found")
+)
+
+// Defaults are the forges known without any configuration.
+var Defaults = []dto.ForgeConfig{
+	{Host: "github.com", Kind: KindGitHub},
+	{Host: "gitlab.com", Kind: KindGitLab},
+	{Host: "gitea.com", Kind: KindGitea},
+	{Host: "codeberg.org", Kind: KindGitea},
+}
+
+// parses ssh, http, and https remotes
+var remoteRe = regexp.MustCompile(`^.+(@|://)(?P<service>[^/:]+)[:/](?P<user>.+)/(?P<repo>[^/]+?)(\.git)?/?$`)
+
+// Repository is a repository hosted on a forge.
+type Repository struct {
+	Forge dto.ForgeConfig
+	Owner string
+	Name  string
+}
+
+// HomeURL is the web page of the repository.
+func (r Repository) HomeURL() string {
+	return "https://" + r.Forge.Host + "/" + r.Owner + "/" + r.Name + "/"
+}
+
+// Key identifies the repository across forges, e.g. for caching.
+func (r Repository) Key() string {
+	return r.Forge.Kind + ":" + r.Forge.Host + "/" + r.Owner + "/" + r.Name
+}
+
+// Forges merges the configured forges over the defaults, matching by host.
+func Forges(configured []dto.ForgeConfig) []dto.ForgeConfig {
+	forges := append([]dto.ForgeConfig(nil), configured...)
+
+	for _, def := range Defaults {
+		found := false
+
+		for _, cfg := range configured {
+			if strings.EqualFold(cfg.Host, def.Host) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			forges = append(forges, def)
+		}
+	}
+
+	return forges
+}
+
+// Parse matches a remote url against the known forges.
+func Parse(url string, forges []dto.ForgeConfig) (Repository, bool) {
+	matches := remoteRe.FindStringSubmatch(url)
+	if matches == nil {
+		return Repository{}, false
+	}
+
+	host := matches[remoteRe.SubexpIndex("service")]
+
+	for _, forge := range forges {
+		if strings.EqualFold(forge.Host, host) {
+			return Repository{
+				Forge: forge,
+				Owner: matches[remoteRe.SubexpIndex("user")],
+				Name:  matches[remoteRe.SubexpIndex("repo")],
+			}, true
+		}
+	}
+
+	return Repository{}, false
+}
+
+// Provider fetches repository metadata from a forge.
+type Provider interface {
+	Repository(ctx context.Context, owner, name string) (dto.RemoteMetadata, error)
+}
+
+// NewProvider returns the Provider for the forge kind. client may be nil to use a client with DefaultTimeout.
+func NewProvider(forge dto.ForgeConfig, client *http.Client) (Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	api := apiClient{client: client, base: strings.TrimSuffix(forge.APIURL, "/"), token: forge.Token}
+
+	switch forge.Kind {
+	case KindGitHub:
+		if api.base == "" {
+			api.base = "https://api." + forge.Host
+			if forge.Host != "github.com" {
+				// github enterprise serves the api from the instance itself
+				api.base = "https://" + forge.Host + "/api/v3"
+			}
+		}
+
+		return &GitHub{api: api}, nil
+	case KindGitLab:
+		if api.base == "" {
+			api.base = "https://" + forge.Host + "/api/v4"
+		}
+
+		return &GitLab{api: api}, nil
+	case KindGitea:
+		if api.base == "" {
+			api.base = "https://" + forge.Host + "/api/v1"
+		}
+
+		return &Gitea{api: api}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, forge.Kind)
+	}
+}
+
+// apiClient performs authenticated JSON requests against a forge API.
+type apiClient struct {
+	client *http.Client
+	base   string
+	token  string
+}
+
+// get decodes the JSON response of GET base+path into out and returns the response headers.
+func (a apiClient) get(ctx context.Context, path string, auth func(*http.Request), out interface{}) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if a.token != "" {
+		auth(req)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from %s: %s", a.base+path, resp.Status)
+	}
+
+	if out == nil {
+		return resp.Header, nil
+	}
+
+	return resp.Header, json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,193 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// providerTests describe the API of every forge kind: the responses it serves for proman/app and how it authenticates.
+var providerTests = []struct {
+	kind      string
+	responses map[string]string
+	headers   http.Header
+	auth      string
+	authValue string
+	want      dto.RemoteMetadata
+}{
+	{
+		kind: KindGitHub,
+		responses: map[string]string{
+			"/repos/proman/app": `{"description": "projects", "default_branch": "main", "stargazers_count": 7,
+				"archived": true}`,
+			"/repos/proman/app/pulls": `[{}]`,
+		},
+		headers: http.Header{"Link": []string{`<https://api.github.com/repositories/1/pulls?state=open&per_page=1&page=2>; ` +
+			`rel="next", <https://api.github.com/repositories/1/pulls?state=open&per_page=1&page=3>; rel="last"`}},
+		auth:      "Authorization",
+		authValue: "Bearer secret",
+		want: dto.RemoteMetadata{
+			Forge: KindGitHub, Description: "projects", DefaultBranch: "main", OpenPullRequests: 3, Stars: 7,
+			Archived: true,
+		},
+	},
+	{
+		kind: KindGitLab,
+		responses: map[string]string{
+			"/projects/proman/app":                `{"description": "projects", "default_branch": "trunk", "star_count": 2}`,
+			"/projects/proman/app/merge_requests": `[{}]`,
+		},
+		headers:   http.Header{"X-Total": []string{"5"}},
+		auth:      "PRIVATE-TOKEN",
+		authValue: "secret",
+		want: dto.RemoteMetadata{
+			Forge: KindGitLab, Description: "projects", DefaultBranch: "trunk", OpenPullRequests: 5, Stars: 2,
+		},
+	},
+	{
+		kind: KindGitea,
+		responses: map[string]string{
+			"/repos/proman/app": `{"description": "projects", "default_branch": "main", "stars_count": 4,
+				"open_pr_counter": 1}`,
+		},
+		auth:      "Authorization",
+		authValue: "token secret",
+		want: dto.RemoteMetadata{
+			Forge: KindGitea, Description: "projects", DefaultBranch: "main", OpenPullRequests: 1, Stars: 4,
+		},
+	},
+}
+
+// newServer serves responses by path, answering 404 for any other path. Requests whose auth header doesn't hold value
+// are rejected with 401.
+func newServer(t *testing.T, responses map[string]string, headers http.Header, auth, value string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(auth) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		for name, values := range headers {
+			w.Header()[name] = values
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestProviders(t *testing.T) {
+	for _, tt := range providerTests {
+		t.Run(tt.kind, func(t *testing.T) {
+			server := newServer(t, tt.responses, tt.headers, tt.auth, tt.authValue)
+
+			provider, err := NewProvider(dto.ForgeConfig{Host: "forge.test", Kind: tt.kind, APIURL: server.URL,
+				Token: "secret"}, server.Client())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			meta, err := provider.Repository(context.Background(), "proman", "app")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if meta != tt.want {
+				t.Errorf("metadata %+v, want %+v", meta, tt.want)
+			}
+
+			_, err = provider.Repository(context.Background(), "proman", "missing")
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("error %v for a missing repository, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestProvidersWithoutToken(t *testing.T) {
+	for _, tt := range providerTests {
+		t.Run(tt.kind, func(t *testing.T) {
+			server := newServer(t, tt.responses, tt.headers, tt.auth, "")
+
+			provider, err := NewProvider(dto.ForgeConfig{Host: "forge.test", Kind: tt.kind, APIURL: server.URL},
+				server.Client())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// the server rejects requests carrying the header, so this only passes when none is sent
+			_, err = provider.Repository(context.Background(), "proman", "app")
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestNewProviderUnknownKind(t *testing.T) {
+	_, err := NewProvider(dto.ForgeConfig{Host: "forge.test", Kind: "bitbucket"}, nil)
+	if !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("error %v, want %v", err, ErrUnknownKind)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Repository
+		ok   bool
+	}{
+		{url: "git@github.com:proman/app.git", want: Repository{Forge: Defaults[0], Owner: "proman", Name: "app"}, ok: true},
+		{url: "https://gitlab.com/group/sub/app", want: Repository{Forge: Defaults[1], Owner: "group/sub", Name: "app"},
+			ok: true},
+		{url: "ssh://git@codeberg.org/proman/app.git/", want: Repository{Forge: Defaults[3], Owner: "proman", Name: "app"},
+			ok: true},
+		{url: "https://example.com/proman/app.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			repo, ok := Parse(tt.url, Defaults)
+			if ok != tt.ok || repo != tt.want {
+				t.Errorf("parsed %+v, %t, want %+v, %t", repo, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLastPage(t *testing.T) {
+	tests := []struct {
+		link string
+		want int
+		ok   bool
+	}{
+		{link: `<https://api.github.com/repositories/1/pulls?per_page=1&page=12>; rel="last"`, want: 12, ok: true},
+		{link: `<https://api.github.com/repositories/1/pulls?page=1>; rel="prev", ` +
+			`<https://api.github.com/repositories/1/pulls?page=1>; rel="first"`},
+		{link: ""},
+	}
+
+	for _, tt := range tests {
+		page, ok := lastPage(tt.link)
+		if page != tt.want || ok != tt.ok {
+			t.Errorf("last page of %q is %d, %v, want %d, %v", tt.link, page, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// Gitea fetches metadata from the Gitea REST API, which Forgejo and Codeberg also serve.
+type Gitea struct {
+	api apiClient
+}
+
+type giteaRepository struct {
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	Stars         int    `json:"stars_count"`
+	Archived      bool   `json:"archived"`
+	OpenPRs       int    `json:"open_pr_counter"`
+}
+
+func (g *Gitea) Repository(ctx context.Context, owner, name string) (dto.RemoteMetadata, error) {
+	var repo giteaRepository
+
+	_, err := g.api.get(ctx, "/repos/"+owner+"/"+name, g.auth, &repo)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	return dto.RemoteMetadata{
+		Forge:            KindGitea,
+		Description:      repo.Description,
+		DefaultBranch:    repo.DefaultBranch,
+		OpenPullRequests: repo.OpenPRs,
+		Stars:            repo.Stars,
+		Archived:         repo.Archived,
+	}, nil
+}
+
+func (g *Gitea) auth(req *http.Request) {
+	req.Header.Set("Authorization", "token "+g.api.token)
+}
@@ -0,0 +1,81 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// GitHub fetches metadata from the GitHub REST API.
+type GitHub struct {
+	api apiClient
+}
+
+type githubRepository struct {
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	Stars         int    `json:"stargazers_count"`
+	Archived      bool   `json:"archived"`
+}
+
+func (g *GitHub) Repository(ctx context.Context, owner, name string) (dto.RemoteMetadata, error) {
+	var repo githubRepository
+
+	_, err := g.api.get(ctx, "/repos/"+owner+"/"+name, g.auth, &repo)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	// the repository's open_issues_count includes pull requests, so they are counted on their own from the pagination
+	// headers of a single pull request per page
+	var pulls []struct{}
+
+	headers, err := g.api.get(ctx, "/repos/"+owner+"/"+name+"/pulls?state=open&per_page=1", g.auth, &pulls)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	open, ok := lastPage(headers.Get("Link"))
+	if !ok {
+		open = len(pulls)
+	}
+
+	return dto.RemoteMetadata{
+		Forge:            KindGitHub,
+		Description:      repo.Description,
+		DefaultBranch:    repo.DefaultBranch,
+		OpenPullRequests: open,
+		Stars:            repo.Stars,
+		Archived:         repo.Archived,
+	}, nil
+}
+
+func (g *GitHub) auth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+g.api.token)
+}
+
+// lastPage returns the number of the page a Link header links as last, false when it links none, which GitHub leaves
+// out when everything fits on the first page.
+func lastPage(link string) (int, bool) {
+	for _, part := range strings.Split(link, ",") {
+		fields := strings.SplitN(part, ";", 2)
+		if len(fields) != 2 || !strings.Contains(fields[1], `rel="last"`) {
+			continue
+		}
+
+		target, err := url.Parse(strings.Trim(strings.TrimSpace(fields[0]), "<>"))
+		if err != nil {
+			return 0, false
+		}
+
+		page, err := strconv.Atoi(target.Query().Get("page"))
+
+		return page, err == nil
+	}
+
+	return 0, false
+}
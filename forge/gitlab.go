@@ -0,0 +1,54 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// GitLab fetches metadata from the GitLab REST API.
+type GitLab struct {
+	api apiClient
+}
+
+type gitlabProject struct {
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	Stars         int    `json:"star_count"`
+	Archived      bool   `json:"archived"`
+}
+
+func (g *GitLab) Repository(ctx context.Context, owner, name string) (dto.RemoteMetadata, error) {
+	id := "/projects/" + url.PathEscape(owner+"/"+name)
+
+	var project gitlabProject
+
+	_, err := g.api.get(ctx, id, g.auth, &project)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	// the merge request count comes from the pagination headers so only a single result is requested
+	headers, err := g.api.get(ctx, id+"/merge_requests?state=opened&per_page=1", g.auth, nil)
+	if err != nil {
+		return dto.RemoteMetadata{}, err
+	}
+
+	open, _ := strconv.Atoi(headers.Get("X-Total"))
+
+	return dto.RemoteMetadata{
+		Forge:            KindGitLab,
+		Description:      project.Description,
+		DefaultBranch:    project.DefaultBranch,
+		OpenPullRequests: open,
+		Stars:            project.Stars,
+		Archived:         project.Archived,
+	}, nil
+}
+
+func (g *GitLab) auth(req *http.Request) {
+	req.Header.Set("PRIVATE-TOKEN", g.api.token)
+}
@@ -0,0 +1,57 @@
+package forge
+
+import (
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// FilePlaceholder stands in for a file path in dto.Links.File.
+const FilePlaceholder = "{file}"
+
+// forgePaths are the web paths of repository pages, relative to the repository home page, for each kind of forge.
+var forgePaths = map[string]struct {
+	pulls, issues, ci, compare, file string
+}{
+	KindGitHub: {"pulls", "issues", "actions", "compare/", "blob/"},
+	KindGitLab: {"-/merge_requests", "-/issues", "-/pipelines", "-/compare/", "-/blob/"},
+	KindGitea:  {"pulls", "issues", "actions", "compare/", "src/branch/"},
+}
+
+// Links derives the web pages of the repository. branch is the checked out branch and base the branch it is compared
+// against. The compare and file links are left blank when branch is blank, and compare also when base is blank or the
+// same as branch.
+func (r Repository) Links(branch, base string) dto.Links {
+	home := r.HomeURL()
+	links := dto.Links{Home: home}
+
+	paths, ok := forgePaths[r.Forge.Kind]
+	if !ok {
+		return links
+	}
+
+	links.PullRequests = home + paths.pulls
+	links.Issues = home + paths.issues
+	links.CI = home + paths.ci
+
+	if branch == "" {
+		return links
+	}
+
+	if base != "" && base != branch {
+		links.Compare = home + paths.compare + base + "..." + branch
+	}
+
+	links.File = home + paths.file + branch + "/" + FilePlaceholder
+
+	return links
+}
+
+// FileURL fills in the file placeholder of a file link. file is relative to the repository root.
+func FileURL(links dto.Links, file string) string {
+	if links.File == "" {
+		return ""
+	}
+
+	return strings.Replace(links.File, FilePlaceholder, strings.TrimPrefix(file, "/"), 1)
+}
@@ -0,0 +1,9 @@
+// Package frontend embeds the built frontend for shells which serve it from an embed.FS.
+package frontend
+
+import "embed"
+
+// Public is the public directory, holding index.html and the bundle built into public/build.
+//
+//go:embed public
+var Public embed.FS
@@ -0,0 +1,515 @@
+// Code generated by go generate ./events. DO NOT EDIT.
+
+/**
+ * @typedef {Object} ActionRun
+ * @property {string} path
+ * @property {string} action
+ */
+
+/**
+ * @typedef {Object} BatchProgress
+ * @property {BatchResult} result
+ * @property {number} done
+ * @property {number} total
+ */
+
+/**
+ * @typedef {Object} BatchRequest
+ * @property {string} operation
+ * @property {ProjectSelector} selector
+ * @property {string} [branch]
+ * @property {number} [concurrency]
+ */
+
+/**
+ * @typedef {Object} BatchResult
+ * @property {string} path
+ * @property {string} operation
+ * @property {string} [message]
+ * @property {string} [error]
+ * @property {number} duration
+ */
+
+/**
+ * @typedef {Object} ConfigSchema
+ * @property {string} project_directory
+ * @property {boolean} setup_complete
+ * @property {boolean} start_minimized
+ * @property {Array<string>} hidden_patterns
+ * @property {number} health_stale_days
+ * @property {number} health_stash_days
+ * @property {number} health_large_file_mb
+ * @property {number} disk_scan_interval_hours
+ * @property {number} history_max_age_days
+ * @property {number} history_max_entries
+ * @property {Array<ForgeConfig>} forges
+ * @property {number} forge_cache_ttl_minutes
+ * @property {string} secrets_backend
+ */
+
+/**
+ * @typedef {Object} DiskUsage
+ * @property {number} source
+ * @property {number} git
+ * @property {Object<string, number>} [artifacts]
+ * @property {number} other
+ * @property {number} total
+ * @property {string} scanned_at
+ * @property {string} mod_time
+ */
+
+/**
+ * @typedef {Object} Editor
+ * @property {string} [icon]
+ * @property {string} path
+ * @property {string} name
+ * @property {boolean} [default]
+ */
+
+/**
+ * @typedef {Object} EventError
+ * @property {string} event
+ * @property {string} code
+ * @property {string} message
+ */
+
+/**
+ * @typedef {Object} EventResult
+ * @property {string} id
+ * @property {?Failure} [error]
+ */
+
+/**
+ * @typedef {Object} Failure
+ * @property {string} code
+ * @property {string} message
+ * @property {Array<FieldError>} [fields]
+ */
+
+/**
+ * @typedef {Object} FieldError
+ * @property {string} field
+ * @property {string} message
+ */
+
+/**
+ * @typedef {Object} ForgeConfig
+ * @property {string} host
+ * @property {string} kind
+ * @property {string} [api_url]
+ */
+
+/**
+ * @typedef {Object} ProjectSelector
+ * @property {Array<string>} [tags]
+ * @property {string} [root]
+ * @property {string} [query]
+ */
+
+/**
+ * @typedef {Object} RemoteMetadata
+ * @property {string} forge
+ * @property {string} [description]
+ * @property {string} [default_branch]
+ * @property {number} open_pull_requests
+ * @property {number} stars
+ * @property {boolean} archived
+ * @property {string} fetched_at
+ * @property {boolean} [stale]
+ */
+
+/**
+ * @typedef {Object} SetupState
+ * @property {string} step
+ * @property {boolean} complete
+ * @property {boolean} degraded
+ * @property {string} [reason]
+ * @property {Array<string>} candidates
+ * @property {Array<Editor>} editors
+ */
+
+const pending = {};
+const listening = {};
+let lastRequestID = 0;
+
+const settle = (result) => {
+	const request = pending[result.id];
+	if (!request) {
+		return;
+	}
+
+	delete pending[result.id];
+
+	if (result.error) {
+		request.reject(result.error);
+	} else {
+		request.resolve();
+	}
+};
+
+const request = (name, ...args) => {
+	if (!listening[name]) {
+		listening[name] = true;
+		window.wails.Events.On(name + ".result", settle);
+	}
+
+	const id = String(++lastRequestID);
+
+	return new Promise((resolve, reject) => {
+		pending[id] = { resolve, reject };
+		window.wails.Events.Emit(name, ...args, id);
+	});
+};
+
+/**
+ * Parses the error a bound method rejected with. Errors which aren't a Failure are described as internal.
+ * @param {*} error
+ * @returns {Failure}
+ */
+export const parseError = (error) => {
+	try {
+		const failure = JSON.parse(error);
+		if (failure && failure.code) {
+			return failure;
+		}
+	} catch (_) {
+		// not a Failure
+	}
+
+	return { code: "internal", message: String(error) };
+};
+
+/** Opens a url in the browser */
+export const OpenURL = "OpenURL";
+
+/**
+ * Emits OpenURL. Opens a url in the browser.
+ * @param {string} url
+ */
+export const emitOpenURL = (url) => window.wails.Events.Emit(OpenURL, url);
+
+/** Opens a project with its editor */
+export const OpenProject = "OpenProject";
+
+/**
+ * Emits OpenProject. Opens a project with its editor.
+ * @param {string} path
+ */
+export const emitOpenProject = (path) => window.wails.Events.Emit(OpenProject, path);
+
+/**
+ * Sends OpenProject and waits for its result. Rejects with the Failure of the request.
+ * @param {string} path
+ * @returns {Promise<void>}
+ */
+export const requestOpenProject = (path) => request(OpenProject, path);
+
+/** Starts a git operation across a selection of projects, answered once it was accepted */
+export const ProjectsBatch = "projects.batch";
+
+/**
+ * Emits projects.batch. Starts a git operation across a selection of projects, answered once it was accepted.
+ * @param {BatchRequest} request
+ */
+export const emitProjectsBatch = (request) => window.wails.Events.Emit(ProjectsBatch, request);
+
+/**
+ * Sends projects.batch and waits for its result. Rejects with the Failure of the request.
+ * @param {BatchRequest} request
+ * @returns {Promise<void>}
+ */
+export const requestProjectsBatch = (request) => request(ProjectsBatch, request);
+
+/** Validates and writes config keys */
+export const ConfigUpdate = "config.update";
+
+/**
+ * Emits config.update. Validates and writes config keys.
+ * @param {Object<string, *>} config
+ */
+export const emitConfigUpdate = (config) => window.wails.Events.Emit(ConfigUpdate, config);
+
+/**
+ * Sends config.update and waits for its result. Rejects with the Failure of the request.
+ * @param {Object<string, *>} config
+ * @returns {Promise<void>}
+ */
+export const requestConfigUpdate = (config) => request(ConfigUpdate, config);
+
+/** Asks the user to pick a project directory */
+export const ConfigSelectProjectDirectory = "config.select_project_directory";
+
+/**
+ * Emits config.select_project_directory. Asks the user to pick a project directory.
+ */
+export const emitConfigSelectProjectDirectory = () => window.wails.Events.Emit(ConfigSelectProjectDirectory);
+
+/**
+ * Sends config.select_project_directory and waits for its result. Rejects with the Failure of the request.
+ * @returns {Promise<void>}
+ */
+export const requestConfigSelectProjectDirectory = () => request(ConfigSelectProjectDirectory);
+
+/** Validates config keys, answered with validate.config.completed */
+export const ValidateConfig = "validate.config";
+
+/**
+ * Emits validate.config. Validates config keys, answered with validate.config.completed.
+ * @param {Object<string, *>} config
+ */
+export const emitValidateConfig = (config) => window.wails.Events.Emit(ValidateConfig, config);
+
+/** Creates or updates an editor */
+export const EditorUpsert = "editor.upsert";
+
+/**
+ * Emits editor.upsert. Creates or updates an editor.
+ * @param {Editor} editor
+ */
+export const emitEditorUpsert = (editor) => window.wails.Events.Emit(EditorUpsert, editor);
+
+/**
+ * Sends editor.upsert and waits for its result. Rejects with the Failure of the request.
+ * @param {Editor} editor
+ * @returns {Promise<void>}
+ */
+export const requestEditorUpsert = (editor) => request(EditorUpsert, editor);
+
+/** Removes an editor by name */
+export const EditorRemove = "editor.remove";
+
+/**
+ * Emits editor.remove. Removes an editor by name.
+ * @param {string} name
+ */
+export const emitEditorRemove = (name) => window.wails.Events.Emit(EditorRemove, name);
+
+/**
+ * Sends editor.remove and waits for its result. Rejects with the Failure of the request.
+ * @param {string} name
+ * @returns {Promise<void>}
+ */
+export const requestEditorRemove = (name) => request(EditorRemove, name);
+
+/** Runs a custom action against a project */
+export const ActionRun = "action.run";
+
+/**
+ * Emits action.run. Runs a custom action against a project.
+ * @param {ActionRun} run
+ */
+export const emitActionRun = (run) => window.wails.Events.Emit(ActionRun, run);
+
+/**
+ * Sends action.run and waits for its result. Rejects with the Failure of the request.
+ * @param {ActionRun} run
+ * @returns {Promise<void>}
+ */
+export const requestActionRun = (run) => request(ActionRun, run);
+
+/** A request event failed to decode, validate or run */
+export const RequestFailed = "request.failed";
+
+/**
+ * Listens for request.failed. A request event failed to decode, validate or run.
+ * @param {function(EventError): void} callback
+ */
+export const onRequestFailed = (callback) => window.wails.Events.On(RequestFailed, callback);
+
+/** The config file was edited by hand */
+export const ConfigChanged = "config.changed";
+
+/**
+ * Listens for config.changed. The config file was edited by hand.
+ * @param {function(ConfigSchema, ConfigSchema): void} callback
+ */
+export const onConfigChanged = (callback) => window.wails.Events.On(ConfigChanged, callback);
+
+/** A hand edit of the config file was rejected */
+export const ConfigInvalid = "config.invalid";
+
+/**
+ * Listens for config.invalid. A hand edit of the config file was rejected.
+ * @param {function(string, Array<FieldError>): void} callback
+ */
+export const onConfigInvalid = (callback) => window.wails.Events.On(ConfigInvalid, callback);
+
+/** A project directory was picked */
+export const ConfigSetProjectDirectory = "config.set_project_directory";
+
+/**
+ * Listens for config.set_project_directory. A project directory was picked.
+ * @param {function(string, string): void} callback
+ */
+export const onConfigSetProjectDirectory = (callback) => window.wails.Events.On(ConfigSetProjectDirectory, callback);
+
+/** The result of validate.config */
+export const ValidateConfigCompleted = "validate.config.completed";
+
+/**
+ * Listens for validate.config.completed. The result of validate.config.
+ * @param {function(Array<FieldError>): void} callback
+ */
+export const onValidateConfigCompleted = (callback) => window.wails.Events.On(ValidateConfigCompleted, callback);
+
+/** Groups or saved views changed */
+export const GroupsChanged = "groups.changed";
+
+/**
+ * Listens for groups.changed. Groups or saved views changed.
+ * @param {function(): void} callback
+ */
+export const onGroupsChanged = (callback) => window.wails.Events.On(GroupsChanged, callback);
+
+/** Another launch of proman was forwarded to this one */
+export const InstanceFocus = "instance.focus";
+
+/**
+ * Listens for instance.focus. Another launch of proman was forwarded to this one.
+ * @param {function(): void} callback
+ */
+export const onInstanceFocus = (callback) => window.wails.Events.On(InstanceFocus, callback);
+
+/** Another launch of proman asked for the quick launcher */
+export const LauncherShow = "launcher.show";
+
+/**
+ * Listens for launcher.show. Another launch of proman asked for the quick launcher.
+ * @param {function(): void} callback
+ */
+export const onLauncherShow = (callback) => window.wails.Events.On(LauncherShow, callback);
+
+/** Profiles were created, deleted or switched */
+export const ProfilesChanged = "profiles.changed";
+
+/**
+ * Listens for profiles.changed. Profiles were created, deleted or switched.
+ * @param {function(): void} callback
+ */
+export const onProfilesChanged = (callback) => window.wails.Events.On(ProfilesChanged, callback);
+
+/** The listed projects changed and should be refetched */
+export const ProjectsChanged = "projects.changed";
+
+/**
+ * Listens for projects.changed. The listed projects changed and should be refetched.
+ * @param {function(): void} callback
+ */
+export const onProjectsChanged = (callback) => window.wails.Events.On(ProjectsChanged, callback);
+
+/** A project in a batch finished */
+export const ProjectsBatchProgress = "projects.batch.progress";
+
+/**
+ * Listens for projects.batch.progress. A project in a batch finished.
+ * @param {function(BatchProgress): void} callback
+ */
+export const onProjectsBatchProgress = (callback) => window.wails.Events.On(ProjectsBatchProgress, callback);
+
+/** A batch finished, with the result of every project */
+export const ProjectsBatchCompleted = "projects.batch.completed";
+
+/**
+ * Listens for projects.batch.completed. A batch finished, with the result of every project.
+ * @param {function(Array<BatchResult>): void} callback
+ */
+export const onProjectsBatchCompleted = (callback) => window.wails.Events.On(ProjectsBatchCompleted, callback);
+
+/** The disk usage of a project was measured */
+export const ProjectsDiskUsage = "projects.disk_usage";
+
+/**
+ * Listens for projects.disk_usage. The disk usage of a project was measured.
+ * @param {function(string, DiskUsage): void} callback
+ */
+export const onProjectsDiskUsage = (callback) => window.wails.Events.On(ProjectsDiskUsage, callback);
+
+/** The forge metadata of a project was fetched */
+export const ProjectsRemote = "projects.remote";
+
+/**
+ * Listens for projects.remote. The forge metadata of a project was fetched.
+ * @param {function(string, RemoteMetadata): void} callback
+ */
+export const onProjectsRemote = (callback) => window.wails.Events.On(ProjectsRemote, callback);
+
+/** Secrets were stored, deleted or unlocked */
+export const SecretsChanged = "secrets.changed";
+
+/**
+ * Listens for secrets.changed. Secrets were stored, deleted or unlocked.
+ * @param {function(): void} callback
+ */
+export const onSecretsChanged = (callback) => window.wails.Events.On(SecretsChanged, callback);
+
+/** First run setup progressed */
+export const SetupChanged = "setup.changed";
+
+/**
+ * Listens for setup.changed. First run setup progressed.
+ * @param {function(SetupState): void} callback
+ */
+export const onSetupChanged = (callback) => window.wails.Events.On(SetupChanged, callback);
+
+/** The result of OpenProject */
+export const OpenProjectResult = "OpenProject.result";
+
+/**
+ * Listens for OpenProject.result. The result of OpenProject.
+ * @param {function(EventResult): void} callback
+ */
+export const onOpenProjectResult = (callback) => window.wails.Events.On(OpenProjectResult, callback);
+
+/** The result of projects.batch */
+export const ProjectsBatchResult = "projects.batch.result";
+
+/**
+ * Listens for projects.batch.result. The result of projects.batch.
+ * @param {function(EventResult): void} callback
+ */
+export const onProjectsBatchResult = (callback) => window.wails.Events.On(ProjectsBatchResult, callback);
+
+/** The result of config.update */
+export const ConfigUpdateResult = "config.update.result";
+
+/**
+ * Listens for config.update.result. The result of config.update.
+ * @param {function(EventResult): void} callback
+ */
+export const onConfigUpdateResult = (callback) => window.wails.Events.On(ConfigUpdateResult, callback);
+
+/** The result of config.select_project_directory */
+export const ConfigSelectProjectDirectoryResult = "config.select_project_directory.result";
+
+/**
+ * Listens for config.select_project_directory.result. The result of config.select_project_directory.
+ * @param {function(EventResult): void} callback
+ */
+export const onConfigSelectProjectDirectoryResult = (callback) => window.wails.Events.On(ConfigSelectProjectDirectoryResult, callback);
+
+/** The result of editor.upsert */
+export const EditorUpsertResult = "editor.upsert.result";
+
+/**
+ * Listens for editor.upsert.result. The result of editor.upsert.
+ * @param {function(EventResult): void} callback
+ */
+export const onEditorUpsertResult = (callback) => window.wails.Events.On(EditorUpsertResult, callback);
+
+/** The result of editor.remove */
+export const EditorRemoveResult = "editor.remove.result";
+
+/**
+ * Listens for editor.remove.result. The result of editor.remove.
+ * @param {function(EventResult): void} callback
+ */
+export const onEditorRemoveResult = (callback) => window.wails.Events.On(EditorRemoveResult, callback);
+
+/** The result of action.run */
+export const ActionRunResult = "action.run.result";
+
+/**
+ * Listens for action.run.result. The result of action.run.
+ * @param {function(EventResult): void} callback
+ */
+export const onActionRunResult = (callback) => window.wails.Events.On(ActionRunResult, callback);
@@ -3,9 +3,12 @@ module github.com/mattouille/proman
 go 1.16
 
 require (
+	github.com/fsnotify/fsnotify v1.5.1
 	github.com/go-git/go-git/v5 v5.4.2
+	github.com/godbus/dbus/v5 v5.0.4
 	github.com/mitchellh/mapstructure v1.4.2
 	github.com/spf13/viper v1.9.0
 	github.com/wailsapp/wails v1.16.7
 	go.etcd.io/bbolt v1.3.6
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
 )
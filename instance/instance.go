@@ -0,0 +1,155 @@
+// Package instance keeps proman to a single running instance. The first instance listens on a local socket, later
+// instances forward their request to it over the socket and exit.
+package instance
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// SocketName is the name of the socket in the state directory.
+const SocketName = "proman.sock"
+
+// DialTimeout bounds how long forwarding a request to the running instance may take.
+const DialTimeout = 2 * time.Second
+
+const (
+	// CommandFocus asks the running instance to bring itself to the front
+	CommandFocus = "focus"
+	// CommandOpen asks the running instance to open the project at Request.Path
+	CommandOpen = "open"
+	// CommandLauncher asks the running instance to show the quick launcher
+	CommandLauncher = "launcher"
+	// CommandAction asks the running instance to run the custom action Request.Action against the project at
+	// Request.Path
+	CommandAction = "action"
+)
+
+var (
+	ErrRunning        = errors.New("proman is already running")
+	ErrUnknownCommand = errors.New("unknown instance command")
+	ErrNoResponse     = errors.New("the running proman instance did not respond")
+)
+
+// Request is sent by a later instance to the running one.
+type Request struct {
+	Command string `json:"command"`
+	// Path is the project to open for CommandOpen, or to run the action against for CommandAction
+	Path string `json:"path,omitempty"`
+	// Action is the name of the custom action to run for CommandAction
+	Action string `json:"action,omitempty"`
+}
+
+// Response answers a Request. Error is blank when the request succeeded.
+type Response struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Handler handles requests forwarded from later instances.
+type Handler func(req Request) error
+
+// Server is the listening side held by the running instance.
+type Server struct {
+	listener net.Listener
+	path     string
+}
+
+// Listen claims the socket at path for this instance. It returns ErrRunning when another instance is listening. A
+// socket left behind by an instance which crashed is removed and claimed.
+func Listen(path string) (*Server, error) {
+	conn, err := net.DialTimeout("unix", path, DialTimeout)
+	if err == nil {
+		_ = conn.Close()
+
+		return nil, ErrRunning
+	}
+
+	// nothing answered, so any socket file is stale
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{listener: listener, path: path}, nil
+}
+
+// Serve handles requests with handler until the server is closed.
+func (s *Server) Serve(handler Handler) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn, handler)
+	}
+}
+
+func (s *Server) handle(conn net.Conn, handler Handler) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(DialTimeout))
+
+	var (
+		req  Request
+		resp Response
+	)
+
+	err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req)
+	if err == nil {
+		err = handler(req)
+	}
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// Close stops listening and removes the socket.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	_ = os.Remove(s.path)
+
+	return err
+}
+
+// Send forwards req to the instance listening at path and returns the error it reported.
+func Send(path string, req Request) error {
+	conn, err := net.DialTimeout("unix", path, DialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(DialTimeout))
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return err
+	}
+
+	var resp Response
+
+	err = json.NewDecoder(conn).Decode(&resp)
+	if err != nil {
+		return ErrNoResponse
+	}
+
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+
+	return nil
+}
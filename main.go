@@ -2,11 +2,11 @@ package main
 
 import (
 	_ "embed"
+	"errors"
 	"log"
 	"os"
 
-	"github.com/mattouille/proman/service/config"
-	"github.com/mattouille/proman/service/database"
+	"github.com/mattouille/proman/core"
 	"github.com/wailsapp/wails"
 )
 
@@ -34,26 +34,31 @@ func main() {
 		Colour:    "#131313",
 	})
 
-	// configuration automatically loads, but does need to be read in and checked for errors.
-	err := config.ReadInConfig()
-	if err != nil {
-		log.Printf("Unable to load configuration: %s", err)
+	// proman's own flags are removed from the arguments before wails parses them
+	var opts core.Options
+	opts, os.Args = core.ParseArgs(os.Args)
 
-		os.Exit(1)
+	services, err := core.Load(opts)
+	if errors.Is(err, core.ErrForwarded) {
+		os.Exit(0)
 	}
 
-	// start a new database connection
-	err = database.New()
 	if err != nil {
-		log.Printf("Unable to start database service: %s", err)
+		log.Printf("Unable to start proman: %s", err)
 
 		os.Exit(1)
 	}
 
-	app.Bind(NewConfig())
-	app.Bind(NewValidator())
-	app.Bind(NewProjects())
-	app.Bind(NewEditorConfig())
+	app.Bind(&Config{services.Config})
+	app.Bind(&Validate{services.Validate})
+	app.Bind(&Projects{services.Projects})
+	app.Bind(&Setup{services.Setup})
+	app.Bind(&Groups{services.Groups})
+	app.Bind(&EditorConfig{services.EditorConfig})
+	app.Bind(&Profiles{services.Profiles})
+	app.Bind(&Secrets{services.Secrets})
+	app.Bind(&Actions{services.Actions})
+	app.Bind(&Instance{services.Instance})
 
 	err = app.Run()
 	if err != nil {
@@ -13,35 +13,33 @@ var (
 	ErrTargetBlank = errors.New("target cannot be blank")
 )
 
-// ExpandAndValidate expands a directory, resolving ~ and relative references, and then validates that the directory
-// exists. It returns the absolute path and any errors.
-func ExpandAndValidate(path string) (string, error) {
+// Expand resolves ~ and relative references in path and returns the absolute path. Unlike ExpandAndValidate the
+// target doesn't need to exist.
+func Expand(path string) (string, error) {
 	if path == "" {
 		return "", ErrTargetBlank
 	}
 
-	usr, err := user.Current()
-	home := usr.HomeDir
-
 	// deal with ~
-	if path == "~" {
-		if err != nil {
-			return "", err
-		}
-		// In case of "~", which won't be caught by the "else if"
-		path = home
-	} else if strings.HasPrefix(path, "~/") {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		usr, err := user.Current()
 		if err != nil {
 			return "", err
 		}
 
 		// Use strings.HasPrefix so we don't match paths like
 		// "/something/~/something/"
-		path = filepath.Join(home, path[2:])
+		path = filepath.Join(usr.HomeDir, path[1:])
 	}
 
 	// resolves '..', '.', and relative paths
-	abs, err := filepath.Abs(path)
+	return filepath.Abs(path)
+}
+
+// ExpandAndValidate expands a directory, resolving ~ and relative references, and then validates that the directory
+// exists. It returns the absolute path and any errors.
+func ExpandAndValidate(path string) (string, error) {
+	abs, err := Expand(path)
 	if err != nil {
 		return "", err
 	}
@@ -51,6 +49,10 @@ func ExpandAndValidate(path string) (string, error) {
 		return "", fmt.Errorf("target directory does not exist")
 	}
 
+	if err != nil {
+		return "", err
+	}
+
 	if !file.IsDir() {
 		return "", fmt.Errorf("target is not a directory")
 	}
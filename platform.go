@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/mattouille/proman/platform"
+	"github.com/wailsapp/wails"
+	"github.com/wailsapp/wails/lib/logger"
+)
+
+// newPlatform adapts the runtime wails passes to WailsInit for the core services.
+func newPlatform(runtime *wails.Runtime) *platform.Runtime {
+	return &platform.Runtime{
+		Events:  runtime.Events,
+		Dialog:  runtime.Dialog,
+		Browser: runtime.Browser,
+		Log: func(name string) platform.Logger {
+			return wailsLogger{runtime.Log.New(name)}
+		},
+	}
+}
+
+// wailsLogger converts the fields of messages for the wails logger.
+type wailsLogger struct {
+	*logger.CustomLogger
+}
+
+func (l wailsLogger) DebugFields(message string, fields platform.Fields) {
+	l.CustomLogger.DebugFields(message, logger.Fields(fields))
+}
+
+func (l wailsLogger) ErrorFields(message string, fields platform.Fields) {
+	l.CustomLogger.ErrorFields(message, logger.Fields(fields))
+}
@@ -0,0 +1,175 @@
+// Package fake is an in-memory platform for running the services without a window. Everything the services do with it
+// is recorded for inspection.
+package fake
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mattouille/proman/platform"
+)
+
+// Platform holds the fakes behind a platform.Runtime.
+type Platform struct {
+	Events  *Events
+	Dialog  *Dialog
+	Browser *Browser
+	Logger  *Logger
+}
+
+// New returns a platform with no events registered, a dialog which is always cancelled and a browser which opens
+// nothing.
+func New() *Platform {
+	return &Platform{
+		Events:  &Events{handlers: make(map[string][]func(...interface{}))},
+		Dialog:  new(Dialog),
+		Browser: new(Browser),
+		Logger:  new(Logger),
+	}
+}
+
+// Runtime returns the runtime the services are started with. Every service shares the one logger.
+func (p *Platform) Runtime() *platform.Runtime {
+	return &platform.Runtime{
+		Events:  p.Events,
+		Dialog:  p.Dialog,
+		Browser: p.Browser,
+		Log: func(string) platform.Logger {
+			return p.Logger
+		},
+	}
+}
+
+// Event is an emitted event.
+type Event struct {
+	Name string
+	Data []interface{}
+}
+
+// Events records emitted events and delivers them to the registered callbacks. Unlike wails, which runs every callback
+// in its own goroutine, callbacks run before Emit returns, so their effects can be checked right after emitting.
+type Events struct {
+	mu       sync.Mutex
+	handlers map[string][]func(...interface{})
+	emitted  []Event
+}
+
+func (e *Events) On(eventName string, callback func(optionalData ...interface{})) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.handlers[eventName] = append(e.handlers[eventName], callback)
+}
+
+func (e *Events) Emit(eventName string, optionalData ...interface{}) {
+	e.mu.Lock()
+	e.emitted = append(e.emitted, Event{Name: eventName, Data: optionalData})
+	handlers := append([]func(...interface{}){}, e.handlers[eventName]...)
+	e.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(optionalData...)
+	}
+}
+
+// Emitted returns the data of every emission of the event called name, oldest first.
+func (e *Events) Emitted(name string) [][]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var data [][]interface{}
+
+	for _, event := range e.emitted {
+		if event.Name == name {
+			data = append(data, event.Data)
+		}
+	}
+
+	return data
+}
+
+// Reset forgets the emitted events, keeping the callbacks.
+func (e *Events) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.emitted = nil
+}
+
+// Dialog picks Directory whenever it is asked for one.
+type Dialog struct {
+	Directory string
+}
+
+func (d *Dialog) SelectDirectory() string {
+	return d.Directory
+}
+
+// Browser records the urls it was asked to open and fails with Err.
+type Browser struct {
+	mu   sync.Mutex
+	urls []string
+	Err  error
+}
+
+func (b *Browser) OpenURL(url string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.urls = append(b.urls, url)
+
+	return b.Err
+}
+
+// Opened returns the urls opened so far.
+func (b *Browser) Opened() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]string{}, b.urls...)
+}
+
+// Logger records log messages as lines of the level, message and fields.
+type Logger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *Logger) Info(message string) {
+	l.record("info", message)
+}
+
+func (l *Logger) Debug(message string) {
+	l.record("debug", message)
+}
+
+func (l *Logger) Debugf(message string, args ...interface{}) {
+	l.record("debug", fmt.Sprintf(message, args...))
+}
+
+func (l *Logger) DebugFields(message string, fields platform.Fields) {
+	l.record("debug", fmt.Sprintf("%s %v", message, fields))
+}
+
+func (l *Logger) Errorf(message string, args ...interface{}) {
+	l.record("error", fmt.Sprintf(message, args...))
+}
+
+func (l *Logger) ErrorFields(message string, fields platform.Fields) {
+	l.record("error", fmt.Sprintf("%s %v", message, fields))
+}
+
+func (l *Logger) record(level, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines = append(l.lines, level+" "+message)
+}
+
+// Lines returns the messages logged so far.
+func (l *Logger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string{}, l.lines...)
+}
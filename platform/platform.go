@@ -0,0 +1,43 @@
+// Package platform is what the services need from the UI framework: events, dialogs, the browser and logging. The
+// services only hold these interfaces, so they run on the wails runtime in the app and on the in-memory fakes of the
+// fake package without a window.
+package platform
+
+// Events sends events to the frontend and to other services, and receives them.
+type Events interface {
+	On(eventName string, callback func(optionalData ...interface{}))
+	Emit(eventName string, optionalData ...interface{})
+}
+
+// Dialog asks the user for input.
+type Dialog interface {
+	// SelectDirectory returns the directory the user picked, or a blank string when they cancelled
+	SelectDirectory() string
+}
+
+// Browser opens urls outside of proman.
+type Browser interface {
+	OpenURL(url string) error
+}
+
+// Fields are structured values logged with a message.
+type Fields map[string]interface{}
+
+// Logger is the logging the services use.
+type Logger interface {
+	Info(message string)
+	Debug(message string)
+	Debugf(message string, args ...interface{})
+	DebugFields(message string, fields Fields)
+	Errorf(message string, args ...interface{})
+	ErrorFields(message string, fields Fields)
+}
+
+// Runtime is the platform a service runs on.
+type Runtime struct {
+	Events  Events
+	Dialog  Dialog
+	Browser Browser
+	// Log returns a logger whose messages are prefixed with name
+	Log func(name string) Logger
+}
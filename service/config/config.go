@@ -3,16 +3,37 @@ package config
 import (
 	"fmt"
 	"os"
-	"os/user"
+	"path/filepath"
+	"sync"
 
+	"github.com/mattouille/proman/dirs"
 	"github.com/mattouille/proman/dto"
 	"github.com/spf13/viper"
 )
 
-const (
-	ConfigPath = "/.config/proman"
-	ConfigName = "/config.toml"
-)
+// ConfigName is the name of the config file in the config directory.
+const ConfigName = "config.toml"
+
+// defaults are applied to keys missing from the config file
+var defaults = map[string]interface{}{
+	"setup_complete":  false,
+	"start_minimized": false,
+
+	"hidden_patterns": []string{},
+
+	"health_stale_days":    7,
+	"health_stash_days":    30,
+	"health_large_file_mb": 50,
+
+	"disk_scan_interval_hours": 24,
+
+	"history_max_age_days": 365,
+	"history_max_entries":  100,
+
+	"forge_cache_ttl_minutes": 60,
+
+	"secrets_backend": "file",
+}
 
 var (
 	c *Config
@@ -25,11 +46,22 @@ func init() {
 // New creates a new config service
 func New() *Config {
 	c = new(Config)
-	c.viper = viper.New()
+	c.viper = newViper()
 
 	return c
 }
 
+// newViper returns a viper instance with the defaults applied.
+func newViper() *viper.Viper {
+	v := viper.New()
+
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	return v
+}
+
 // Service returns an instance of the config service.
 func Service() *Config {
 	return c
@@ -37,30 +69,25 @@ func Service() *Config {
 
 // Config is the Config Service
 type Config struct {
+	// mu guards viper, which is replaced when the config file is edited
+	mu    sync.RWMutex
 	viper *viper.Viper
 }
 
-// ReadInConfig reads configuration from a specified location
-func ReadInConfig() error { return c.ReadInConfig() }
+// ReadInConfig reads configuration from file, which is created if it doesn't exist. A blank file reads config.toml from
+// the config directory, moving it there from the legacy location first if needed.
+func ReadInConfig(file string) error { return c.ReadInConfig(file) }
 
-func (c *Config) ReadInConfig() error {
-	usr, err := user.Current()
+func (c *Config) ReadInConfig(file string) error {
+	cfg, err := configFile(file)
 	if err != nil {
-		return fmt.Errorf("unable to determine current user: %w", err)
+		return err
 	}
 
-	home := usr.HomeDir
-
-	path := home + ConfigPath
-	cfg := path + ConfigName
-
 	// make sure the config directory exists
-	_, err = os.Stat(path)
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(path, 0o775) //nolint:gomnd
-		if err != nil {
-			return fmt.Errorf("unable to create proman directory: %w", err)
-		}
+	_, err = dirs.Ensure(filepath.Dir(cfg))
+	if err != nil {
+		return err
 	}
 
 	// make sure the config file exists
@@ -77,23 +104,54 @@ func (c *Config) ReadInConfig() error {
 		}
 	}
 
-	c.viper.SetConfigFile(cfg)
+	// a fresh instance drops the values set by MergeConfigMap for the previous file
+	next := newViper()
+	next.SetConfigFile(cfg)
 
-	err = c.viper.ReadInConfig()
+	err = next.ReadInConfig()
 	if err != nil {
 		return fmt.Errorf("unable to read in config: %w", err)
 	}
 
+	c.mu.Lock()
+	c.viper = next
+	c.mu.Unlock()
+
 	return nil
 }
 
+func configFile(file string) (string, error) {
+	if file != "" {
+		return filepath.Abs(file)
+	}
+
+	dir, err := dirs.Config()
+	if err != nil {
+		return "", err
+	}
+
+	err = dirs.Migrate(ConfigName, dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, ConfigName), nil
+}
+
 // Unmarshal unmarshals config using mapstructure
 func Unmarshal() (dto.ConfigSchema, error) { return c.Unmarshal() }
 
 func (c *Config) Unmarshal() (dto.ConfigSchema, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return unmarshal(c.viper)
+}
+
+func unmarshal(v *viper.Viper) (dto.ConfigSchema, error) {
 	cfg := dto.ConfigSchema{}
 
-	err := c.viper.Unmarshal(&cfg)
+	err := v.Unmarshal(&cfg)
 	if err != nil {
 		return dto.ConfigSchema{}, err
 	}
@@ -105,12 +163,24 @@ func (c *Config) Unmarshal() (dto.ConfigSchema, error) {
 func MergeConfigMap(data map[string]interface{}) error { return c.MergeConfigMap(data) }
 
 func (c *Config) MergeConfigMap(data map[string]interface{}) error {
-	return c.viper.MergeConfigMap(data)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// viper.MergeConfigMap skips values whose type differs from the one read from the file, such as numbers decoded
+	// from JSON, so every key is set instead
+	for key, value := range data {
+		c.viper.Set(key, value)
+	}
+
+	return nil
 }
 
 // WriteConfig writes the configuration back to disk
 func WriteConfig() error { return c.WriteConfig() }
 
 func (c *Config) WriteConfig() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.viper.WriteConfig()
 }
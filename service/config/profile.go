@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mattouille/proman/dirs"
+)
+
+const (
+	// DefaultProfile keeps its files directly in the config and data directories
+	DefaultProfile = "default"
+	// ProfilesDir is the directory, inside the config and data directories, other profiles are kept in
+	ProfilesDir = "profiles"
+	// activeProfileName is the file in the config directory recording the active profile
+	activeProfileName = "profile"
+)
+
+var (
+	ErrInvalidProfile  = errors.New("profile names may only contain letters, numbers, dashes and underscores")
+	ErrProfileExists   = errors.New("profile already exists")
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileInUse    = errors.New("the active and default profiles cannot be deleted")
+)
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateProfileName returns ErrInvalidProfile unless name can be used as a profile name.
+func ValidateProfileName(name string) error {
+	if !profileName.MatchString(name) {
+		return ErrInvalidProfile
+	}
+
+	return nil
+}
+
+// profileDir returns the directory of profile inside base.
+func profileDir(base, profile string) string {
+	if profile == DefaultProfile {
+		return base
+	}
+
+	return filepath.Join(base, ProfilesDir, profile)
+}
+
+// ProfileConfigFile returns the config file of profile.
+func ProfileConfigFile(profile string) (string, error) {
+	if profile == DefaultProfile {
+		return configFile("")
+	}
+
+	dir, err := dirs.Config()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(profileDir(dir, profile), ConfigName), nil
+}
+
+// ProfileDataDir returns the directory the database and other data of profile are kept in.
+func ProfileDataDir(profile string) (string, error) {
+	dir, err := dirs.Data()
+	if err != nil {
+		return "", err
+	}
+
+	return profileDir(dir, profile), nil
+}
+
+// Profiles returns the names of all profiles, the default profile first.
+func Profiles() ([]string, error) {
+	dir, err := dirs.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, ProfilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, f := range files {
+		if f.IsDir() && ValidateProfileName(f.Name()) == nil {
+			names = append(names, f.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// ProfileExists reports whether profile exists.
+func ProfileExists(profile string) (bool, error) {
+	profiles, err := Profiles()
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range profiles {
+		if name == profile {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ActiveProfile returns the profile proman starts with. It falls back to the default profile when the recorded one no
+// longer exists.
+func ActiveProfile() (string, error) {
+	dir, err := dirs.Config()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, activeProfileName))
+	if os.IsNotExist(err) {
+		return DefaultProfile, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	profile := strings.TrimSpace(string(data))
+
+	exists, err := ProfileExists(profile)
+	if err != nil {
+		return "", err
+	}
+
+	if !exists {
+		return DefaultProfile, nil
+	}
+
+	return profile, nil
+}
+
+// SetActiveProfile records profile as the one proman starts with.
+func SetActiveProfile(profile string) error {
+	dir, err := dirs.Config()
+	if err != nil {
+		return err
+	}
+
+	_, err = dirs.Ensure(dir)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, activeProfileName), []byte(profile+"\n"), 0o600)
+}
+
+// CreateProfile creates profile with an empty config.
+func CreateProfile(profile string) error {
+	err := ValidateProfileName(profile)
+	if err != nil {
+		return err
+	}
+
+	exists, err := ProfileExists(profile)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrProfileExists
+	}
+
+	file, err := ProfileConfigFile(profile)
+	if err != nil {
+		return err
+	}
+
+	_, err = dirs.Ensure(filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, nil, 0o600)
+}
+
+// DeleteProfile removes profile along with its config and data. The default and active profiles cannot be deleted.
+func DeleteProfile(profile string) error {
+	active, err := ActiveProfile()
+	if err != nil {
+		return err
+	}
+
+	if profile == DefaultProfile || profile == active {
+		return ErrProfileInUse
+	}
+
+	exists, err := ProfileExists(profile)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrProfileNotFound
+	}
+
+	file, err := ProfileConfigFile(profile)
+	if err != nil {
+		return err
+	}
+
+	data, err := ProfileDataDir(profile)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range []string{filepath.Dir(file), data} {
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return fmt.Errorf("unable to remove profile %s: %w", profile, err)
+		}
+	}
+
+	return nil
+}
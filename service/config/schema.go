@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattouille/proman/dto"
+	"github.com/mattouille/proman/forge"
+	"github.com/mattouille/proman/path"
+	"github.com/mattouille/proman/service/secrets"
+)
+
+// Kind is the type of value a config key holds.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindInt
+	KindStringList
+	KindForges
+)
+
+// Field describes the values a config key accepts.
+type Field struct {
+	Kind Kind
+	// Min and Max bound KindInt values, inclusive
+	Min, Max int
+	// Enum lists the accepted KindString values, empty accepts any
+	Enum []string
+	// Directory requires a KindString value to be an existing directory
+	Directory bool
+	// Glob requires every value of a KindStringList to be a valid glob pattern
+	Glob bool
+}
+
+// Schema describes every key of dto.ConfigSchema.
+var Schema = map[string]Field{
+	"project_directory": {Kind: KindString, Directory: true},
+	"setup_complete":    {Kind: KindBool},
+	"start_minimized":   {Kind: KindBool},
+	"hidden_patterns":   {Kind: KindStringList, Glob: true},
+
+	"health_stale_days":    {Kind: KindInt, Min: 1, Max: 3650},
+	"health_stash_days":    {Kind: KindInt, Min: 1, Max: 3650},
+	"health_large_file_mb": {Kind: KindInt, Min: 1, Max: 102400},
+
+	"disk_scan_interval_hours": {Kind: KindInt, Min: 1, Max: 8760},
+
+	"history_max_age_days": {Kind: KindInt, Min: 0, Max: 36500},
+	"history_max_entries":  {Kind: KindInt, Min: 1, Max: 100000},
+
+	"forges":                  {Kind: KindForges},
+	"forge_cache_ttl_minutes": {Kind: KindInt, Min: 1, Max: 10080},
+
+	"secrets_backend": {Kind: KindString, Enum: []string{secrets.BackendFile, secrets.BackendSecretService}},
+}
+
+// ValidationError is returned when config fails validation. It holds an error for every invalid key.
+type ValidationError []dto.FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+
+	for i, field := range e {
+		msgs[i] = field.Field + ": " + field.Message
+	}
+
+	return "invalid config: " + strings.Join(msgs, ", ")
+}
+
+// Validate checks the keys present in data against Schema and returns an error for every invalid or unknown key,
+// ordered by key. Keys missing from data are not checked, so partial updates can be validated.
+func Validate(data map[string]interface{}) []dto.FieldError {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	errs := []dto.FieldError{}
+
+	for _, key := range keys {
+		field, ok := Schema[key]
+		if !ok {
+			errs = append(errs, dto.FieldError{Field: key, Message: "unknown setting"})
+
+			continue
+		}
+
+		errs = append(errs, field.validate(key, data[key])...)
+	}
+
+	return errs
+}
+
+func (f Field) validate(key string, value interface{}) []dto.FieldError {
+	invalid := func(format string, args ...interface{}) []dto.FieldError {
+		return []dto.FieldError{{Field: key, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	switch f.Kind {
+	case KindString:
+		s, ok := value.(string)
+		if !ok {
+			return invalid("must be a string")
+		}
+
+		if len(f.Enum) > 0 && !contains(f.Enum, s) {
+			return invalid("must be one of %s", strings.Join(f.Enum, ", "))
+		}
+
+		if f.Directory {
+			_, err := path.ExpandAndValidate(s)
+			if err != nil {
+				return invalid("%s", err)
+			}
+		}
+	case KindBool:
+		if _, ok := value.(bool); !ok {
+			return invalid("must be true or false")
+		}
+	case KindInt:
+		n, ok := toInt(value)
+		if !ok {
+			return invalid("must be a whole number")
+		}
+
+		if n < f.Min || n > f.Max {
+			return invalid("must be between %d and %d", f.Min, f.Max)
+		}
+	case KindStringList:
+		list, ok := toStrings(value)
+		if !ok {
+			return invalid("must be a list of strings")
+		}
+
+		if f.Glob {
+			var errs []dto.FieldError
+
+			for i, pattern := range list {
+				_, err := filepath.Match(pattern, "")
+				if err != nil {
+					errs = append(errs, dto.FieldError{Field: fmt.Sprintf("%s[%d]", key, i), Message: "invalid glob pattern"})
+				}
+			}
+
+			return errs
+		}
+	case KindForges:
+		return validateForges(key, value)
+	}
+
+	return nil
+}
+
+func validateForges(key string, value interface{}) []dto.FieldError {
+	var entries []map[string]interface{}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []map[string]interface{}:
+		entries = v
+	case []interface{}:
+		for _, entry := range v {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				return []dto.FieldError{{Field: key, Message: "must be a list of forges"}}
+			}
+
+			entries = append(entries, m)
+		}
+	default:
+		return []dto.FieldError{{Field: key, Message: "must be a list of forges"}}
+	}
+
+	var errs []dto.FieldError
+
+	for i, entry := range entries {
+		prefix := fmt.Sprintf("%s[%d].", key, i)
+
+		invalid := func(name, msg string) {
+			errs = append(errs, dto.FieldError{Field: prefix + name, Message: msg})
+		}
+
+		for name, v := range entry {
+			s, ok := v.(string)
+			if !ok {
+				invalid(name, "must be a string")
+
+				continue
+			}
+
+			switch name {
+			case "host":
+				if s == "" || strings.ContainsAny(s, "/ ") {
+					invalid(name, "must be a hostname")
+				}
+			case "kind":
+				if !contains([]string{forge.KindGitHub, forge.KindGitLab, forge.KindGitea}, s) {
+					invalid(name, "must be one of github, gitlab, gitea")
+				}
+			case "api_url":
+				u, err := url.Parse(s)
+				if s != "" && (err != nil || u.Scheme == "" || u.Host == "") {
+					invalid(name, "must be an absolute url")
+				}
+			case "token":
+				invalid(name, "must be kept in the secret store, not the config file")
+			default:
+				invalid(name, "unknown setting")
+			}
+		}
+
+		if _, ok := entry["host"]; !ok {
+			invalid("host", "is required")
+		}
+
+		if _, ok := entry["kind"]; !ok {
+			invalid("kind", "is required")
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+
+	return errs
+}
+
+// toInt accepts the number types produced by the TOML and JSON decoders.
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// toStrings accepts string lists as decoded from TOML and JSON. null is an empty list.
+func toStrings(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case []string:
+		return v, true
+	case []interface{}:
+		list := make([]string, len(v))
+
+		for i := range v {
+			s, ok := v[i].(string)
+			if !ok {
+				return nil, false
+			}
+
+			list[i] = s
+		}
+
+		return list, true
+	default:
+		return nil, false
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mattouille/proman/dto"
+)
+
+// settleTime is how long the config file must go without writes before it's reloaded. Editors often save in several
+// steps, which shouldn't be reloaded one by one.
+const settleTime = 250 * time.Millisecond
+
+// Watch reloads the config file whenever it's edited outside of proman. onChange is called with the previous and the
+// new config after a valid edit. Edits are validated against Schema first, an invalid edit is not applied and
+// onInvalid is called with the error instead. The returned function stops watching.
+func Watch(onChange func(previous, current dto.ConfigSchema), onInvalid func(err error)) (func() error, error) {
+	return c.Watch(onChange, onInvalid)
+}
+
+func (c *Config) Watch(onChange func(previous, current dto.ConfigSchema), onInvalid func(err error)) (func() error, error) {
+	c.mu.RLock()
+	file := filepath.Clean(c.viper.ConfigFileUsed())
+	c.mu.RUnlock()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// the directory is watched rather than the file so that editors which save by replacing the file are picked up
+	err = watcher.Add(filepath.Dir(file))
+	if err != nil {
+		_ = watcher.Close()
+
+		return nil, err
+	}
+
+	reload := func() {
+		previous, current, changed, err := c.reload()
+		if err != nil {
+			onInvalid(err)
+
+			return
+		}
+
+		if changed {
+			onChange(previous, current)
+		}
+	}
+
+	go func() {
+		var timer *time.Timer
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if filepath.Clean(event.Name) != file || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+
+				if timer != nil {
+					timer.Stop()
+				}
+
+				timer = time.AfterFunc(settleTime, reload)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				onInvalid(err)
+			}
+		}
+	}()
+
+	return watcher.Close, nil
+}
+
+// reload reads the config file and applies it when every changed key is valid. changed is false when the file matches
+// the config already loaded, which is the case after proman writes the file itself.
+func (c *Config) reload() (previous, current dto.ConfigSchema, changed bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := newViper()
+	next.SetConfigFile(c.viper.ConfigFileUsed())
+
+	err = next.ReadInConfig()
+	if err != nil {
+		return previous, current, false, err
+	}
+
+	// only changed keys are validated so that an existing problem, such as a project directory which was since
+	// removed, doesn't block unrelated edits
+	edited := make(map[string]interface{})
+
+	for key, value := range next.AllSettings() {
+		if !reflect.DeepEqual(value, c.viper.Get(key)) {
+			edited[key] = value
+		}
+	}
+
+	errs := Validate(edited)
+	if len(errs) > 0 {
+		return previous, current, false, ValidationError(errs)
+	}
+
+	previous, err = unmarshal(c.viper)
+	if err != nil {
+		return previous, current, false, err
+	}
+
+	current, err = unmarshal(next)
+	if err != nil {
+		return previous, current, false, err
+	}
+
+	c.viper = next
+
+	return previous, current, !reflect.DeepEqual(previous, current), nil
+}
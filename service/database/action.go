@@ -0,0 +1,115 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+// GetActions fetches all custom actions
+func (d *DB) GetActions() ([]dto.Action, error) {
+	var actions []dto.Action
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(actionBucket).ForEach(func(k, v []byte) error {
+			var tmp dto.Action
+
+			err := json.NewDecoder(bytes.NewReader(v)).Decode(&tmp)
+			if err != nil {
+				return fmt.Errorf("error while decoding %s: %w", k, err)
+			}
+
+			actions = append(actions, tmp)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actions) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	return actions, nil
+}
+
+// UpsertAction creates or replaces a custom action. Name and command are required. Actions are keyed by their scope
+// and name, so a project or tag action can share the name of a global one.
+func (d *DB) UpsertAction(action dto.Action) error {
+	if action.Name == "" {
+		return fmt.Errorf("name is required to upsert")
+	}
+
+	if action.Command == "" {
+		return fmt.Errorf("command is required to upsert")
+	}
+
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(action)
+	if err != nil {
+		return fmt.Errorf("unable to encode action: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		err := deleteLegacyAction(tx.Bucket(actionBucket), action)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(actionBucket).Put(actionKey(action), buff.Bytes())
+	})
+}
+
+// DeleteAction deletes the custom action with the name and scope of action
+func (d *DB) DeleteAction(action dto.Action) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		err := deleteLegacyAction(tx.Bucket(actionBucket), action)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(actionBucket).Delete(actionKey(action))
+	})
+}
+
+// actionKey returns the key of action. Global actions are keyed by name, project and tag actions by their scope, its
+// value and the name, separated by NUL bytes which can't be typed into any of them.
+func actionKey(action dto.Action) []byte {
+	switch {
+	case action.Project != "":
+		return []byte("project\x00" + action.Project + "\x00" + action.Name)
+	case action.Tag != "":
+		return []byte("tag\x00" + action.Tag + "\x00" + action.Name)
+	default:
+		return []byte(action.Name)
+	}
+}
+
+// deleteLegacyAction deletes a scoped action stored by an earlier version under its name only.
+func deleteLegacyAction(bucket *bbolt.Bucket, action dto.Action) error {
+	key := []byte(action.Name)
+	if bytes.Equal(key, actionKey(action)) {
+		return nil
+	}
+
+	data := bucket.Get(key)
+	if data == nil {
+		return nil
+	}
+
+	var stored dto.Action
+
+	err := json.Unmarshal(data, &stored)
+	if err != nil || stored.Tag != action.Tag || stored.Project != action.Project {
+		return nil
+	}
+
+	return bucket.Delete(key)
+}
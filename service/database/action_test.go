@@ -0,0 +1,100 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+func TestActionScopes(t *testing.T) {
+	d := newTestDB(t)
+
+	actions := []dto.Action{
+		{Name: "open", Command: "global"},
+		{Name: "open", Command: "tag", Tag: "go"},
+		{Name: "open", Command: "project", Project: "app"},
+	}
+
+	for _, action := range actions {
+		err := d.UpsertAction(action)
+		if err != nil {
+			t.Fatalf("unable to store %+v: %s", action, err)
+		}
+	}
+
+	// replacing an action keeps the others of the same name
+	err := d.UpsertAction(dto.Action{Name: "open", Command: "tag again", Tag: "go"})
+	if err != nil {
+		t.Fatalf("unable to replace action: %s", err)
+	}
+
+	stored, err := d.GetActions()
+	if err != nil || len(stored) != 3 {
+		t.Fatalf("stored %+v, %v, want an action per scope", stored, err)
+	}
+
+	err = d.DeleteAction(dto.Action{Name: "open", Project: "app"})
+	if err != nil {
+		t.Fatalf("unable to delete action: %s", err)
+	}
+
+	stored, err = d.GetActions()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	commands := map[string]bool{}
+	for _, action := range stored {
+		commands[action.Command] = true
+	}
+
+	if len(stored) != 2 || !commands["global"] || !commands["tag again"] {
+		t.Errorf("stored %+v, want the global and the replaced tag action", stored)
+	}
+}
+
+func TestDeleteLegacyAction(t *testing.T) {
+	d := newTestDB(t)
+
+	// earlier versions keyed every action by name only
+	legacy := dto.Action{Name: "deploy", Command: "make deploy", Tag: "client"}
+
+	data, err := json.Marshal(legacy)
+	if err == nil {
+		err = d.conn().Update(func(tx *bbolt.Tx) error {
+			return tx.Bucket(actionBucket).Put([]byte(legacy.Name), data)
+		})
+	}
+
+	if err != nil {
+		t.Fatalf("unable to store legacy action: %s", err)
+	}
+
+	err = d.UpsertAction(dto.Action{Name: "deploy", Command: "make release", Tag: "client"})
+	if err != nil {
+		t.Fatalf("unable to replace action: %s", err)
+	}
+
+	stored, err := d.GetActions()
+	if err != nil || len(stored) != 1 || stored[0].Command != "make release" {
+		t.Fatalf("stored %+v, %v, want the legacy action replaced", stored, err)
+	}
+
+	err = d.DeleteAction(stored[0])
+	if err != nil {
+		t.Fatalf("unable to delete action: %s", err)
+	}
+
+	_, err = d.GetActions()
+	if err != ErrNoRecords {
+		t.Errorf("error %v after deleting the only action, want %v", err, ErrNoRecords)
+	}
+
+	if !bytes.Equal(actionKey(dto.Action{Name: "x"}), []byte("x")) {
+		t.Errorf("global actions are not keyed by name")
+	}
+}
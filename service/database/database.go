@@ -5,7 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os/user"
+	"sync"
+	"time"
 
 	"github.com/mattouille/proman/dto"
 
@@ -16,12 +17,39 @@ import (
 var (
 	projectBucket = []byte("projects")
 	editorBucket  = []byte("editors")
+	healthBucket  = []byte("health")
+	ruleBucket    = []byte("editor_rules")
+	historyBucket = []byte("history")
+	groupBucket   = []byte("groups")
+	viewBucket    = []byte("views")
+	forgeBucket   = []byte("forge_cache")
+	actionBucket  = []byte("actions")
+
+	// buckets are created by migrate
+	buckets = [][]byte{
+		projectBucket, editorBucket, healthBucket, ruleBucket, historyBucket, groupBucket, viewBucket, forgeBucket,
+		actionBucket,
+	}
 
 	ErrNoRecords = errors.New("no records found")
+	ErrLocked    = errors.New("database is in use by another proman process")
 )
 
 const DefaultDBPermissions = 0o655
 
+// FileName is the name of the database file in the data directory.
+const FileName = "store.db"
+
+// DefaultTimeout is how long opening the database waits for another process to release it.
+const DefaultTimeout = 2 * time.Second
+
+// Options configure how the database is opened. There is no read only mode for other launches: bbolt locks read only
+// opens out while a writer holds the database, so they hand their request to the running instance instead.
+type Options struct {
+	// Timeout is how long to wait for the file lock before failing with ErrLocked. Zero uses DefaultTimeout.
+	Timeout time.Duration
+}
+
 var db *DB
 
 // Service returns an instance of the DB service
@@ -29,46 +57,111 @@ func Service() *DB {
 	return db
 }
 
-// New starts the DB service
-func New() error {
-	usr, _ := user.Current()
-
-	conn, err := bbolt.Open(usr.HomeDir+"/.config/proman/store.db", DefaultDBPermissions, nil)
+// New starts the DB service with the database at path. It fails with ErrLocked when another process holds the
+// database for longer than the timeout.
+func New(path string, opts Options) error {
+	conn, err := open(path, opts)
 	if err != nil {
 		return err
 	}
 
 	svc := new(DB)
 	svc.db = conn
+	svc.opts = opts
 	db = svc
 
-	return db.migrate()
+	return nil
 }
 
 // DB is the database service
 type DB struct {
-	db *bbolt.DB
+	// mu guards db, which is replaced when switching databases
+	mu   sync.RWMutex
+	db   *bbolt.DB
+	opts Options
 }
 
-func (d *DB) migrate() error {
-	return d.db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(projectBucket)
-		if err != nil {
-			return err
-		}
+// open opens and migrates the database at path.
+func open(path string, opts Options) (*bbolt.DB, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
 
-		_, err = tx.CreateBucketIfNotExists(editorBucket)
-		if err != nil {
-			return err
+	conn, err := bbolt.Open(path, DefaultDBPermissions, &bbolt.Options{Timeout: timeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = migrate(conn)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Switch closes the current database and continues with the one at path. The DB service stays the same, so services
+// holding on to it follow the switch. The current database is kept when the new one can't be opened.
+func (d *DB) Switch(path string) error {
+	conn, err := open(path, d.opts)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	prev := d.db
+	d.db = conn
+	d.mu.Unlock()
+
+	// bbolt waits for running transactions before closing
+	return prev.Close()
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.conn().Close()
+}
+
+func (d *DB) conn() *bbolt.DB {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.db
+}
+
+func migrate(conn *bbolt.DB) error {
+	return conn.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			_, err := tx.CreateBucketIfNotExists(bucket)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
 }
 
-// DeleteProject deletes a project by path.
+// DeleteProject deletes a project, its cached health report and its open history by path.
 func (d *DB) DeleteProject(path string) error {
-	return d.db.Update(func(tx *bbolt.Tx) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		err := tx.Bucket(healthBucket).Delete([]byte(path))
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket(historyBucket).DeleteBucket([]byte(path))
+		if err != nil && !errors.Is(err, bbolt.ErrBucketNotFound) {
+			return err
+		}
+
 		return tx.Bucket(projectBucket).Delete([]byte(path))
 	})
 }
@@ -80,7 +173,7 @@ func (d *DB) UpsertProject(input map[string]interface{}) error {
 		return fmt.Errorf("path is required to upsert")
 	}
 
-	return d.db.Update(func(tx *bbolt.Tx) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
 		data := tx.Bucket(projectBucket).Get([]byte(path.(string)))
 		// project doesn't exist in the DB
 		if len(data) == 0 {
@@ -103,7 +196,7 @@ func (d *DB) UpsertProject(input map[string]interface{}) error {
 		}
 
 		// update the project
-		err = mapstructure.Decode(input, &tmp)
+		err = update(input, &tmp)
 		if err != nil {
 			return fmt.Errorf("unable to decode input: %w", err)
 		}
@@ -120,11 +213,22 @@ func (d *DB) UpsertProject(input map[string]interface{}) error {
 	})
 }
 
+// update decodes input over the stored record result. Values in input replace those of result, lists included, where
+// mapstructure would otherwise merge them element by element and never shrink them.
+func update(input map[string]interface{}, result interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{ZeroFields: true, Result: result})
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(input)
+}
+
 // GetAllProjects fetches all projects from the projects table
 func (d *DB) GetAllProjects() ([]dto.Project, error) {
 	var projects []dto.Project
 
-	err := d.db.View(func(tx *bbolt.Tx) error {
+	err := d.conn().View(func(tx *bbolt.Tx) error {
 		return tx.Bucket(projectBucket).ForEach(func(k, v []byte) error {
 			var tmp dto.Project
 
@@ -153,7 +257,7 @@ func (d *DB) GetAllProjects() ([]dto.Project, error) {
 func (d *DB) GetProjectByPath(directory string) (dto.Project, error) {
 	var project dto.Project
 
-	err := d.db.View(func(tx *bbolt.Tx) error {
+	err := d.conn().View(func(tx *bbolt.Tx) error {
 		p := tx.Bucket(projectBucket).Get([]byte(directory))
 
 		// error checking
@@ -174,7 +278,7 @@ func (d *DB) GetProjectByPath(directory string) (dto.Project, error) {
 func (d *DB) GetEditors() ([]dto.Editor, error) {
 	var editors []dto.Editor
 
-	err := d.db.View(func(tx *bbolt.Tx) error {
+	err := d.conn().View(func(tx *bbolt.Tx) error {
 		return tx.Bucket(editorBucket).ForEach(func(k, v []byte) error {
 			var tmp dto.Editor
 
@@ -211,7 +315,7 @@ func (d *DB) UpsertEditor(input map[string]interface{}) error {
 		return fmt.Errorf("name is required to upsert")
 	}
 
-	return d.db.Update(func(tx *bbolt.Tx) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
 		data := tx.Bucket(editorBucket).Get([]byte(name.(string)))
 		// editor doesn't exist in the DB
 		if len(data) == 0 {
@@ -234,7 +338,7 @@ func (d *DB) UpsertEditor(input map[string]interface{}) error {
 		}
 
 		// update the editor
-		err = mapstructure.Decode(input, &tmp)
+		err = update(input, &tmp)
 		if err != nil {
 			return fmt.Errorf("unable to decode input: %w", err)
 		}
@@ -253,7 +357,7 @@ func (d *DB) UpsertEditor(input map[string]interface{}) error {
 
 // DeleteEditor deletes an editor by name
 func (d *DB) DeleteEditor(name string) error {
-	return d.db.Update(func(tx *bbolt.Tx) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
 		return tx.Bucket(editorBucket).Delete([]byte(name))
 	})
 }
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+)
+
+func TestNewLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	err := New(path, Options{})
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err)
+	}
+
+	held := Service()
+	defer held.Close()
+
+	start := time.Now()
+
+	err = New(path, Options{Timeout: 50 * time.Millisecond})
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("error %v, want %v", err, ErrLocked)
+	}
+
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("waited %s for the lock, want the timeout to apply", waited)
+	}
+
+	if Service() != held {
+		t.Errorf("a failed open replaced the service")
+	}
+}
+
+func TestUpsertProjectReplacesLists(t *testing.T) {
+	d := newTestDB(t)
+
+	err := d.UpsertProject(map[string]interface{}{
+		"path":      "app",
+		"tags":      []string{"a", "b", "c"},
+		"auto_tags": []string{"go", "node"},
+		"languages": []dto.Language{{Name: "go"}, {Name: "node"}},
+	})
+	if err != nil {
+		t.Fatalf("unable to insert project: %s", err)
+	}
+
+	err = d.UpsertProject(map[string]interface{}{
+		"path":      "app",
+		"tags":      []string{"x"},
+		"auto_tags": []string{},
+		"languages": []dto.Language{},
+	})
+	if err != nil {
+		t.Fatalf("unable to update project: %s", err)
+	}
+
+	err = d.UpsertProject(map[string]interface{}{"path": "app", "pinned": true})
+	if err != nil {
+		t.Fatalf("unable to update project: %s", err)
+	}
+
+	projects, err := d.GetAllProjects()
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("read projects %v, %v, want app", projects, err)
+	}
+
+	project := projects[0]
+
+	if !reflect.DeepEqual(project.Tags, []string{"x"}) {
+		t.Errorf("tags %v, want [x]", project.Tags)
+	}
+
+	if len(project.AutoTags) != 0 || len(project.Languages) != 0 {
+		t.Errorf("auto tags %v and languages %v, want them cleared", project.AutoTags, project.Languages)
+	}
+
+	if !project.Pinned {
+		t.Errorf("the project was not pinned")
+	}
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+// GetForgeMetadata fetches cached forge metadata by repository key. Expired entries are returned as well, it is up to
+// the caller to check FetchedAt.
+func (d *DB) GetForgeMetadata(key string) (dto.RemoteMetadata, error) {
+	var meta dto.RemoteMetadata
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(forgeBucket).Get([]byte(key))
+
+		if len(data) == 0 {
+			return ErrNoRecords
+		}
+
+		return json.NewDecoder(bytes.NewReader(data)).Decode(&meta)
+	})
+
+	return meta, err
+}
+
+// PutForgeMetadata caches forge metadata by repository key
+func (d *DB) PutForgeMetadata(key string, meta dto.RemoteMetadata) error {
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(meta)
+	if err != nil {
+		return fmt.Errorf("unable to encode forge metadata: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(forgeBucket).Put([]byte(key), buff.Bytes())
+	})
+}
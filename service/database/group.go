@@ -0,0 +1,153 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+// GetGroups fetches all project groups ordered by name
+func (d *DB) GetGroups() ([]dto.Group, error) {
+	var groups []dto.Group
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(groupBucket).ForEach(func(k, v []byte) error {
+			var tmp dto.Group
+
+			err := json.NewDecoder(bytes.NewReader(v)).Decode(&tmp)
+			if err != nil {
+				return fmt.Errorf("error while decoding %s: %w", k, err)
+			}
+
+			groups = append(groups, tmp)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	return groups, nil
+}
+
+// GetGroup fetches a project group by name
+func (d *DB) GetGroup(name string) (dto.Group, error) {
+	var group dto.Group
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		g := tx.Bucket(groupBucket).Get([]byte(name))
+
+		if len(g) == 0 {
+			return ErrNoRecords
+		}
+
+		return json.NewDecoder(bytes.NewReader(g)).Decode(&group)
+	})
+
+	return group, err
+}
+
+// UpsertGroup creates or replaces a project group. Name is required.
+func (d *DB) UpsertGroup(group dto.Group) error {
+	if group.Name == "" {
+		return fmt.Errorf("name is required to upsert")
+	}
+
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(group)
+	if err != nil {
+		return fmt.Errorf("unable to encode group: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(groupBucket).Put([]byte(group.Name), buff.Bytes())
+	})
+}
+
+// DeleteGroup deletes a project group by name
+func (d *DB) DeleteGroup(name string) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(groupBucket).Delete([]byte(name))
+	})
+}
+
+// GetViews fetches all saved views ordered by name
+func (d *DB) GetViews() ([]dto.SavedView, error) {
+	var views []dto.SavedView
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(viewBucket).ForEach(func(k, v []byte) error {
+			var tmp dto.SavedView
+
+			err := json.NewDecoder(bytes.NewReader(v)).Decode(&tmp)
+			if err != nil {
+				return fmt.Errorf("error while decoding %s: %w", k, err)
+			}
+
+			views = append(views, tmp)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(views) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	return views, nil
+}
+
+// GetView fetches a saved view by name
+func (d *DB) GetView(name string) (dto.SavedView, error) {
+	var view dto.SavedView
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(viewBucket).Get([]byte(name))
+
+		if len(v) == 0 {
+			return ErrNoRecords
+		}
+
+		return json.NewDecoder(bytes.NewReader(v)).Decode(&view)
+	})
+
+	return view, err
+}
+
+// UpsertView creates or replaces a saved view. Name is required.
+func (d *DB) UpsertView(view dto.SavedView) error {
+	if view.Name == "" {
+		return fmt.Errorf("name is required to upsert")
+	}
+
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(view)
+	if err != nil {
+		return fmt.Errorf("unable to encode view: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(viewBucket).Put([]byte(view.Name), buff.Bytes())
+	})
+}
+
+// DeleteView deletes a saved view by name
+func (d *DB) DeleteView(name string) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(viewBucket).Delete([]byte(name))
+	})
+}
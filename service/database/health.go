@@ -0,0 +1,54 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+// PutHealthReport caches a health report, replacing any previous report for the same project
+func (d *DB) PutHealthReport(report dto.HealthReport) error {
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(report)
+	if err != nil {
+		return fmt.Errorf("unable to encode health report: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(healthBucket).Put([]byte(report.Path), buff.Bytes())
+	})
+}
+
+// GetHealthReports fetches all cached health reports
+func (d *DB) GetHealthReports() ([]dto.HealthReport, error) {
+	var reports []dto.HealthReport
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(healthBucket).ForEach(func(k, v []byte) error {
+			var tmp dto.HealthReport
+
+			err := json.NewDecoder(bytes.NewReader(v)).Decode(&tmp)
+			if err != nil {
+				return fmt.Errorf("error while decoding %s: %w", k, err)
+			}
+
+			reports = append(reports, tmp)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reports) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	return reports, nil
+}
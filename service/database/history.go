@@ -0,0 +1,141 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+// The history bucket holds a nested bucket per project path. Events are keyed by the big endian unix nanosecond time
+// they happened at so that cursors walk them oldest first.
+
+// RecordOpen appends an open event to the history of the project it refers to
+func (d *DB) RecordOpen(event dto.OpenEvent) error {
+	if event.Path == "" {
+		return fmt.Errorf("path is required to record an open")
+	}
+
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode open event: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(event.Path))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(historyKey(event.OpenedAt), buff.Bytes())
+	})
+}
+
+// GetHistory fetches the open history of every project, keyed by project path and ordered oldest first
+func (d *DB) GetHistory() (map[string][]dto.OpenEvent, error) {
+	history := make(map[string][]dto.OpenEvent)
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(historyBucket).ForEach(func(path, _ []byte) error {
+			return tx.Bucket(historyBucket).Bucket(path).ForEach(func(k, v []byte) error {
+				var tmp dto.OpenEvent
+
+				err := json.NewDecoder(bytes.NewReader(v)).Decode(&tmp)
+				if err != nil {
+					return fmt.Errorf("error while decoding %s: %w", k, err)
+				}
+
+				history[string(path)] = append(history[string(path)], tmp)
+
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(history) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	return history, nil
+}
+
+// PruneHistory deletes open events older than before and keeps at most keep of the most recent events per project.
+// A zero before keeps events of any age and a keep of zero or less any number of events. It returns the number of
+// events deleted.
+func (d *DB) PruneHistory(before time.Time, keep int) (int, error) {
+	deleted := 0
+	cutoff := historyKey(before)
+
+	err := d.conn().Update(func(tx *bbolt.Tx) error {
+		stale := make(map[string][][]byte)
+		empty := make(map[string]bool)
+
+		// collect first, buckets must not be modified while they are being iterated
+		err := tx.Bucket(historyBucket).ForEach(func(path, _ []byte) error {
+			var keys [][]byte
+
+			cursor := tx.Bucket(historyBucket).Bucket(path).Cursor()
+			for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+
+			excess := len(keys) - keep
+
+			for i, k := range keys {
+				if (keep > 0 && i < excess) || (!before.IsZero() && bytes.Compare(k, cutoff) < 0) {
+					stale[string(path)] = append(stale[string(path)], k)
+				}
+			}
+
+			empty[string(path)] = len(stale[string(path)]) == len(keys)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for path, keys := range stale {
+			deleted += len(keys)
+
+			if empty[path] {
+				err := tx.Bucket(historyBucket).DeleteBucket([]byte(path))
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			bucket := tx.Bucket(historyBucket).Bucket([]byte(path))
+
+			for _, k := range keys {
+				err := bucket.Delete(k)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return deleted, err
+}
+
+func historyKey(t time.Time) []byte {
+	key := make([]byte, binary.Size(uint64(0)))
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+
+	return key
+}
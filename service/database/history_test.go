@@ -0,0 +1,83 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattouille/proman/dto"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	err := New(filepath.Join(t.TempDir(), FileName), Options{})
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err)
+	}
+
+	t.Cleanup(func() { _ = Service().Close() })
+
+	return Service()
+}
+
+func recordOpens(t *testing.T, d *DB, path string, times ...time.Time) {
+	t.Helper()
+
+	for _, at := range times {
+		err := d.RecordOpen(dto.OpenEvent{Path: path, Editor: "vim", OpenedAt: at})
+		if err != nil {
+			t.Fatalf("unable to record open: %s", err)
+		}
+	}
+}
+
+func countOpens(t *testing.T, d *DB, path string) int {
+	t.Helper()
+
+	history, err := d.GetHistory()
+	if err != nil && err != ErrNoRecords {
+		t.Fatalf("unable to read history: %s", err)
+	}
+
+	return len(history[path])
+}
+
+func TestPruneHistory(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-90 * 24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		before  time.Time
+		keep    int
+		deleted int
+		left    int
+	}{
+		{name: "zero before keeps every age", before: time.Time{}, keep: 0, deleted: 0, left: 4},
+		{name: "cutoff deletes older opens", before: now.Add(-30 * 24 * time.Hour), keep: 0, deleted: 2, left: 2},
+		{name: "keep limits the opens", before: time.Time{}, keep: 1, deleted: 3, left: 1},
+		{name: "cutoff and keep combine", before: now.Add(-30 * 24 * time.Hour), keep: 3, deleted: 2, left: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDB(t)
+
+			recordOpens(t, d, "proman", old, old.Add(time.Hour), now.Add(-time.Hour), now)
+
+			deleted, err := d.PruneHistory(tt.before, tt.keep)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if deleted != tt.deleted {
+				t.Errorf("deleted %d opens, want %d", deleted, tt.deleted)
+			}
+
+			if left := countOpens(t, d, "proman"); left != tt.left {
+				t.Errorf("%d opens left, want %d", left, tt.left)
+			}
+		})
+	}
+}
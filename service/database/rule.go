@@ -0,0 +1,69 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mattouille/proman/dto"
+
+	"go.etcd.io/bbolt"
+)
+
+// GetEditorRules fetches all editor rules ordered by name
+func (d *DB) GetEditorRules() ([]dto.EditorRule, error) {
+	var rules []dto.EditorRule
+
+	err := d.conn().View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ruleBucket).ForEach(func(k, v []byte) error {
+			var tmp dto.EditorRule
+
+			err := json.NewDecoder(bytes.NewReader(v)).Decode(&tmp)
+			if err != nil {
+				return fmt.Errorf("error while decoding %s: %w", k, err)
+			}
+
+			rules = append(rules, tmp)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	return rules, nil
+}
+
+// UpsertEditorRule creates or replaces an editor rule. Name and editor are required.
+func (d *DB) UpsertEditorRule(rule dto.EditorRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required to upsert")
+	}
+
+	if rule.Editor == "" {
+		return fmt.Errorf("editor is required to upsert")
+	}
+
+	buff := new(bytes.Buffer)
+
+	err := json.NewEncoder(buff).Encode(rule)
+	if err != nil {
+		return fmt.Errorf("unable to encode editor rule: %w", err)
+	}
+
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ruleBucket).Put([]byte(rule.Name), buff.Bytes())
+	})
+}
+
+// DeleteEditorRule deletes an editor rule by name
+func (d *DB) DeleteEditorRule(name string) error {
+	return d.conn().Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ruleBucket).Delete([]byte(name))
+	})
+}
@@ -0,0 +1,255 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// DefaultFilePermissions keeps the encrypted file readable by its owner only
+	DefaultFilePermissions = 0o600
+
+	fileVersion = 1
+	saltSize    = 16
+	nonceSize   = 24
+	keySize     = 32
+
+	// scrypt parameters recommended for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// encryptedFile is the on disk format of the file store. Data is the JSON encoded map of secrets sealed with
+// secretbox using a key derived from the passphrase and Salt with scrypt.
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// FileStore keeps secrets in a single file encrypted with a passphrase derived key.
+type FileStore struct {
+	path    string
+	mu      sync.Mutex
+	key     *[keySize]byte
+	salt    []byte
+	secrets map[string]string
+}
+
+// NewFileStore returns a locked file store backed by the file at path. The file is created on the first Unlock if it
+// doesn't exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Unlock(passphrase string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		salt := make([]byte, saltSize)
+
+		_, err := io.ReadFull(rand.Reader, salt)
+		if err != nil {
+			return err
+		}
+
+		key, err := deriveKey(passphrase, salt)
+		if err != nil {
+			return err
+		}
+
+		f.key, f.salt, f.secrets = key, salt, make(map[string]string)
+
+		return f.save()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var file encryptedFile
+
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return fmt.Errorf("unable to decode secrets file: %w", err)
+	}
+
+	if file.Version != fileVersion || len(file.Nonce) != nonceSize {
+		return fmt.Errorf("unsupported secrets file version %d", file.Version)
+	}
+
+	key, err := deriveKey(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+
+	var nonce [nonceSize]byte
+
+	copy(nonce[:], file.Nonce)
+
+	plain, ok := secretbox.Open(nil, file.Data, &nonce, key)
+	if !ok {
+		return ErrBadPassphrase
+	}
+
+	secrets := make(map[string]string)
+
+	err = json.Unmarshal(plain, &secrets)
+	if err != nil {
+		return fmt.Errorf("unable to decode secrets: %w", err)
+	}
+
+	f.key, f.salt, f.secrets = key, file.Salt, secrets
+
+	return nil
+}
+
+func (f *FileStore) Locked() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.key == nil
+}
+
+func (f *FileStore) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key == nil {
+		return "", ErrLocked
+	}
+
+	value, ok := f.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (f *FileStore) Set(key, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key == nil {
+		return ErrLocked
+	}
+
+	f.secrets[key] = value
+
+	return f.save()
+}
+
+func (f *FileStore) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key == nil {
+		return ErrLocked
+	}
+
+	delete(f.secrets, key)
+
+	return f.save()
+}
+
+func (f *FileStore) List() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key == nil {
+		return nil, ErrLocked
+	}
+
+	keys := make([]string, 0, len(f.secrets))
+	for key := range f.secrets {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// save encrypts the secrets with a fresh nonce and atomically replaces the file. f.mu must be held.
+func (f *FileStore) save() error {
+	plain, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+
+	var nonce [nonceSize]byte
+
+	_, err = io.ReadFull(rand.Reader, nonce[:])
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encryptedFile{
+		Version: fileVersion,
+		Salt:    f.salt,
+		Nonce:   nonce[:],
+		Data:    secretbox.Seal(nil, plain, &nonce, f.key),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), ".secrets-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), DefaultFilePermissions)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("unable to write secrets file: %w", err)
+	}
+
+	return nil
+}
+
+func deriveKey(passphrase string, salt []byte) (*[keySize]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be blank")
+	}
+
+	derived, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [keySize]byte
+
+	copy(key[:], derived)
+
+	return &key, nil
+}
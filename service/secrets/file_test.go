@@ -0,0 +1,170 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newFile returns the path of a secrets file holding token, encrypted with passphrase.
+func newFile(t *testing.T, passphrase string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), FileName)
+	store := NewFileStore(path)
+
+	err := store.Unlock(passphrase)
+	if err == nil {
+		err = store.Set("forge:github.com", "token")
+	}
+
+	if err != nil {
+		t.Fatalf("unable to create secrets file: %s", err)
+	}
+
+	return path
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := newFile(t, "passphrase")
+
+	store := NewFileStore(path)
+	if !store.Locked() {
+		t.Fatalf("a new store is unlocked, want it locked until Unlock")
+	}
+
+	err := store.Unlock("passphrase")
+	if err != nil {
+		t.Fatalf("unable to unlock: %s", err)
+	}
+
+	err = store.Set("git:example.com", "user:password")
+	if err == nil {
+		err = store.Delete("forge:github.com")
+	}
+
+	if err != nil {
+		t.Fatalf("unable to update secrets: %s", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != DefaultFilePermissions {
+		t.Errorf("file %v, %v, want it readable by its owner only", info, err)
+	}
+
+	reopened := NewFileStore(path)
+
+	err = reopened.Unlock("passphrase")
+	if err != nil {
+		t.Fatalf("unable to unlock again: %s", err)
+	}
+
+	value, err := reopened.Get("git:example.com")
+	if err != nil || value != "user:password" {
+		t.Errorf("got %q, %v, want the stored credentials", value, err)
+	}
+
+	_, err = reopened.Get("forge:github.com")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error %v for a deleted secret, want %v", err, ErrNotFound)
+	}
+
+	keys, err := reopened.List()
+	if err != nil || !reflect.DeepEqual(keys, []string{"git:example.com"}) {
+		t.Errorf("listed %v, %v, want the remaining key", keys, err)
+	}
+}
+
+func TestFileStoreWrongPassphrase(t *testing.T) {
+	store := NewFileStore(newFile(t, "passphrase"))
+
+	err := store.Unlock("wrong")
+	if !errors.Is(err, ErrBadPassphrase) {
+		t.Fatalf("error %v, want %v", err, ErrBadPassphrase)
+	}
+
+	if !store.Locked() {
+		t.Errorf("store unlocked with the wrong passphrase")
+	}
+
+	_, err = store.Get("forge:github.com")
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("error %v, want %v", err, ErrLocked)
+	}
+
+	err = store.Unlock("passphrase")
+	if err != nil {
+		t.Errorf("unable to unlock after a wrong passphrase: %s", err)
+	}
+}
+
+func TestFileStoreCorruptFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(file map[string]interface{}) []byte
+		err     error
+	}{
+		{
+			name:    "not json",
+			corrupt: func(map[string]interface{}) []byte { return []byte("{") },
+		},
+		{
+			name: "unknown version",
+			corrupt: func(file map[string]interface{}) []byte {
+				file["version"] = 2
+
+				data, _ := json.Marshal(file)
+
+				return data
+			},
+		},
+		{
+			name: "tampered data",
+			corrupt: func(file map[string]interface{}) []byte {
+				file["data"] = []byte("not the sealed secrets")
+
+				data, _ := json.Marshal(file)
+
+				return data
+			},
+			err: ErrBadPassphrase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := newFile(t, "passphrase")
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unable to read secrets file: %s", err)
+			}
+
+			var file map[string]interface{}
+
+			err = json.Unmarshal(data, &file)
+			if err == nil {
+				err = ioutil.WriteFile(path, tt.corrupt(file), DefaultFilePermissions)
+			}
+
+			if err != nil {
+				t.Fatalf("unable to corrupt secrets file: %s", err)
+			}
+
+			store := NewFileStore(path)
+
+			err = store.Unlock("passphrase")
+			if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Errorf("error %v, want the corrupt file rejected", err)
+			}
+
+			if !store.Locked() {
+				t.Errorf("store unlocked from a corrupt file")
+			}
+		})
+	}
+}
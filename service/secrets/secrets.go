@@ -0,0 +1,112 @@
+// Package secrets stores credentials such as forge tokens and git passwords outside of the plain text config file.
+package secrets
+
+import (
+	"errors"
+	"sync"
+)
+
+// FileName is the name of the file backend's encrypted file in the data directory.
+const FileName = "secrets.json"
+
+const (
+	BackendFile          = "file"
+	BackendSecretService = "secret-service"
+)
+
+var (
+	ErrNotFound       = errors.New("secret not found")
+	ErrLocked         = errors.New("secret store is locked")
+	ErrBadPassphrase  = errors.New("incorrect passphrase")
+	ErrUnsupported    = errors.New("secret backend is not supported on this platform")
+	ErrUnknownBackend = errors.New("unknown secret backend")
+)
+
+// Store is a backend which holds secrets by key.
+type Store interface {
+	// Get returns the secret stored under key, or ErrNotFound
+	Get(key string) (string, error)
+	// Set stores value under key, replacing any existing secret
+	Set(key, value string) error
+	// Delete removes the secret stored under key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// List returns the keys of all stored secrets
+	List() ([]string, error)
+	// Unlock makes the store usable. Stores which don't need unlocking ignore the passphrase.
+	Unlock(passphrase string) error
+	// Locked reports whether the store must be unlocked before use
+	Locked() bool
+}
+
+var (
+	mu sync.RWMutex
+	s  Store
+)
+
+// New opens the secret store for backend and makes it the secret service. path is where the file backend keeps its
+// encrypted file.
+func New(backend, path string) error {
+	var (
+		store Store
+		err   error
+	)
+
+	switch backend {
+	case "", BackendFile:
+		store = NewFileStore(path)
+	case BackendSecretService:
+		store, err = NewSecretService()
+	default:
+		return ErrUnknownBackend
+	}
+
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	s = store
+	mu.Unlock()
+
+	return nil
+}
+
+// Service returns the secret store, or nil if New hasn't been called.
+func Service() Store {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return s
+}
+
+// Lookup returns the secret stored under key. It reports false when there is no store, the store is locked or the
+// secret doesn't exist, which makes it convenient for optional credentials.
+func Lookup(key string) (string, bool) {
+	store := Service()
+	if store == nil || store.Locked() {
+		return "", false
+	}
+
+	value, err := store.Get(key)
+	if err != nil {
+		return "", false
+	}
+
+	return value, true
+}
+
+// ForgeTokenKey is the key a forge API token is stored under.
+func ForgeTokenKey(host string) string {
+	return "forge:" + host
+}
+
+// GitCredentialKey is the key git credentials for a host are stored under. The value is either "username:password"
+// or a bare token.
+func GitCredentialKey(host string) string {
+	return "git:" + host
+}
+
+// SSHPassphraseKey is the key the passphrase of an ssh private key is stored under.
+func SSHPassphraseKey(keyPath string) string {
+	return "ssh:" + keyPath
+}
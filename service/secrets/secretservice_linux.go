@@ -0,0 +1,243 @@
+package secrets
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	ssDest           = "org.freedesktop.secrets"
+	ssPath           = dbus.ObjectPath("/org/freedesktop/secrets")
+	ssDefault        = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	ssService        = "org.freedesktop.Secret.Service"
+	ssCollection     = "org.freedesktop.Secret.Collection"
+	ssItem           = "org.freedesktop.Secret.Item"
+	ssPrompt         = "org.freedesktop.Secret.Prompt"
+	ssNoPrompt       = dbus.ObjectPath("/")
+	ssPromptTimeout  = 2 * time.Minute
+	ssAttrApp        = "application"
+	ssAttrKey        = "key"
+	ssApplication    = "proman"
+	ssPlainAlgorithm = "plain"
+)
+
+// ssSecret mirrors the Secret struct of the Secret Service API.
+type ssSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// SecretService stores secrets in the desktop keyring over the freedesktop Secret Service D-Bus API, as provided by
+// GNOME Keyring and KWallet. Secrets are kept in the default collection and tagged with an application attribute.
+type SecretService struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// NewSecretService connects to the Secret Service on the session bus.
+func NewSecretService() (*SecretService, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		output  dbus.Variant
+		session dbus.ObjectPath
+	)
+
+	err = conn.Object(ssDest, ssPath).Call(ssService+".OpenSession", 0, ssPlainAlgorithm, dbus.MakeVariant("")).
+		Store(&output, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretService{conn: conn, session: session}, nil
+}
+
+func (s *SecretService) Unlock(_ string) error {
+	if !s.Locked() {
+		return nil
+	}
+
+	var (
+		unlocked []dbus.ObjectPath
+		prompt   dbus.ObjectPath
+	)
+
+	err := s.conn.Object(ssDest, ssPath).Call(ssService+".Unlock", 0, []dbus.ObjectPath{ssDefault}).
+		Store(&unlocked, &prompt)
+	if err != nil {
+		return err
+	}
+
+	return s.prompt(prompt)
+}
+
+func (s *SecretService) Locked() bool {
+	locked, err := s.conn.Object(ssDest, ssDefault).GetProperty(ssCollection + ".Locked")
+	if err != nil {
+		return true
+	}
+
+	value, ok := locked.Value().(bool)
+
+	return !ok || value
+}
+
+func (s *SecretService) Get(key string) (string, error) {
+	item, err := s.find(key)
+	if err != nil {
+		return "", err
+	}
+
+	var secret ssSecret
+
+	err = s.conn.Object(ssDest, item).Call(ssItem+".GetSecret", 0, s.session).Store(&secret)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret.Value), nil
+}
+
+func (s *SecretService) Set(key, value string) error {
+	props := map[string]dbus.Variant{
+		ssItem + ".Label": dbus.MakeVariant(ssApplication + ": " + key),
+		ssItem + ".Attributes": dbus.MakeVariant(map[string]string{
+			ssAttrApp: ssApplication,
+			ssAttrKey: key,
+		}),
+	}
+
+	secret := ssSecret{Session: s.session, Value: []byte(value), ContentType: "text/plain"}
+
+	var item, prompt dbus.ObjectPath
+
+	err := s.conn.Object(ssDest, ssDefault).Call(ssCollection+".CreateItem", 0, props, secret, true).
+		Store(&item, &prompt)
+	if err != nil {
+		return err
+	}
+
+	return s.prompt(prompt)
+}
+
+func (s *SecretService) Delete(key string) error {
+	item, err := s.find(key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var prompt dbus.ObjectPath
+
+	err = s.conn.Object(ssDest, item).Call(ssItem+".Delete", 0).Store(&prompt)
+	if err != nil {
+		return err
+	}
+
+	return s.prompt(prompt)
+}
+
+func (s *SecretService) List() ([]string, error) {
+	items, err := s.search(map[string]string{ssAttrApp: ssApplication})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, item := range items {
+		attrs, err := s.conn.Object(ssDest, item).GetProperty(ssItem + ".Attributes")
+		if err != nil {
+			return nil, err
+		}
+
+		if m, ok := attrs.Value().(map[string]string); ok {
+			keys = append(keys, m[ssAttrKey])
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (s *SecretService) find(key string) (dbus.ObjectPath, error) {
+	items, err := s.search(map[string]string{ssAttrApp: ssApplication, ssAttrKey: key})
+	if err != nil {
+		return "", err
+	}
+
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+
+	return items[0], nil
+}
+
+func (s *SecretService) search(attrs map[string]string) ([]dbus.ObjectPath, error) {
+	if s.Locked() {
+		return nil, ErrLocked
+	}
+
+	var items []dbus.ObjectPath
+
+	err := s.conn.Object(ssDest, ssDefault).Call(ssCollection+".SearchItems", 0, attrs).Store(&items)
+
+	return items, err
+}
+
+// prompt shows a Secret Service prompt, such as the keyring unlock dialog, and waits for the user to complete it.
+func (s *SecretService) prompt(prompt dbus.ObjectPath) error {
+	if prompt == ssNoPrompt || prompt == "" {
+		return nil
+	}
+
+	err := s.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(ssPrompt),
+		dbus.WithMatchMember("Completed"),
+	)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan *dbus.Signal, 1)
+
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	err = s.conn.Object(ssDest, prompt).Call(ssPrompt+".Prompt", 0, "").Err
+	if err != nil {
+		return err
+	}
+
+	timeout := time.After(ssPromptTimeout)
+
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != prompt || len(signal.Body) == 0 {
+				continue
+			}
+
+			if dismissed, ok := signal.Body[0].(bool); ok && dismissed {
+				return ErrLocked
+			}
+
+			return nil
+		case <-timeout:
+			return ErrLocked
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package secrets
+
+// SecretService is only available on linux.
+type SecretService struct {
+	Store
+}
+
+// NewSecretService always fails outside of linux.
+func NewSecretService() (*SecretService, error) {
+	return nil, ErrUnsupported
+}
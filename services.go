@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/mattouille/proman/core"
+	"github.com/wailsapp/wails"
+)
+
+// The core services are bound through these types, which add the wails v1 lifecycle and keep the names the frontend
+// calls them by, window.backend.Projects and so on.
+
+type Config struct{ *core.Config }
+
+func (c *Config) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(c.Config, newPlatform(runtime))
+}
+
+func (c *Config) WailsShutdown() {
+	core.Stop(c.Config)
+}
+
+type Validate struct{ *core.Validate }
+
+func (v *Validate) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(v.Validate, newPlatform(runtime))
+}
+
+type Projects struct{ *core.Projects }
+
+func (p *Projects) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(p.Projects, newPlatform(runtime))
+}
+
+type Setup struct{ *core.Setup }
+
+func (s *Setup) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(s.Setup, newPlatform(runtime))
+}
+
+type Groups struct{ *core.Groups }
+
+func (g *Groups) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(g.Groups, newPlatform(runtime))
+}
+
+type EditorConfig struct{ *core.EditorConfig }
+
+func (e *EditorConfig) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(e.EditorConfig, newPlatform(runtime))
+}
+
+type Profiles struct{ *core.Profiles }
+
+func (p *Profiles) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(p.Profiles, newPlatform(runtime))
+}
+
+type Secrets struct{ *core.Secrets }
+
+func (s *Secrets) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(s.Secrets, newPlatform(runtime))
+}
+
+type Actions struct{ *core.Actions }
+
+func (a *Actions) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(a.Actions, newPlatform(runtime))
+}
+
+// Instance tells the frontend what this launch was asked for.
+type Instance struct{ *core.Instance }
+
+func (i *Instance) WailsInit(runtime *wails.Runtime) error {
+	return core.Start(i.Instance, newPlatform(runtime))
+}
+
+func (i *Instance) WailsShutdown() {
+	core.Stop(i.Instance)
+}
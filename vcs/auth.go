@@ -0,0 +1,103 @@
+package vcs
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+
+	"github.com/mattouille/proman/service/secrets"
+)
+
+// Credentials looks up a stored secret by key, see secrets.Lookup.
+type Credentials func(key string) (string, bool)
+
+// sshKeys are the private keys tried, in order, when no ssh agent is running.
+var sshKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// Auth returns the auth method for a remote url. HTTP(S) remotes use the credentials stored under
+// secrets.GitCredentialKey for the host, or anonymous access when there are none. SSH remotes use the ssh agent when
+// one is running, otherwise the first default private key found in ~/.ssh, decrypted with the passphrase stored under
+// secrets.SSHPassphraseKey if it has one. A nil auth method means go-git's defaults apply.
+func Auth(url string, creds Credentials) (transport.AuthMethod, error) {
+	if creds == nil {
+		creds = secrets.Lookup
+	}
+
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpoint.Protocol {
+	case "http", "https":
+		secret, ok := creds(secrets.GitCredentialKey(endpoint.Host))
+		if !ok {
+			return nil, nil
+		}
+
+		username, password := "git", secret
+		if i := strings.IndexByte(secret, ':'); i >= 0 {
+			username, password = secret[:i], secret[i+1:]
+		}
+
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	case "ssh":
+		username := endpoint.User
+		if username == "" {
+			username = "git"
+		}
+
+		if os.Getenv("SSH_AUTH_SOCK") != "" {
+			return gitssh.NewSSHAgentAuth(username)
+		}
+
+		key := defaultSSHKey()
+		if key == "" {
+			return nil, nil
+		}
+
+		passphrase, _ := creds(secrets.SSHPassphraseKey(key))
+
+		return gitssh.NewPublicKeysFromFile(username, key, passphrase)
+	default:
+		return nil, nil
+	}
+}
+
+// remoteAuth returns the auth method for the first url of the named remote.
+func remoteAuth(repo *git.Repository, name string, creds Credentials) (transport.AuthMethod, error) {
+	remote, err := repo.Remote(name)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	return Auth(urls[0], creds)
+}
+
+func defaultSSHKey() string {
+	usr, err := user.Current()
+	if err != nil {
+		return ""
+	}
+
+	for _, name := range sshKeys {
+		path := filepath.Join(usr.HomeDir, ".ssh", name)
+
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
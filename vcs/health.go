@@ -0,0 +1,361 @@
+package vcs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/mattouille/proman/dto"
+)
+
+// maxUnpushedWalk bounds how many commits are walked when counting unpushed commits.
+const maxUnpushedWalk = 1000
+
+// day is the unit ages are reported in.
+const day = 24 * time.Hour
+
+// HealthOptions holds the thresholds used by Health.
+type HealthOptions struct {
+	// StaleAfter is the age after which uncommitted changes are reported
+	StaleAfter time.Duration
+	// StashStaleAfter is the age after which stashes are reported
+	StashStaleAfter time.Duration
+	// LargeFileSize is the size in bytes above which untracked files are reported
+	LargeFileSize int64
+	// Now is the reference time for age checks
+	Now time.Time
+}
+
+// Health runs the hygiene checks against the repository at path and returns the issues found. A nil repository
+// yields no issues.
+func Health(path string, repo *git.Repository, opts HealthOptions) ([]dto.HealthIssue, error) {
+	if repo == nil {
+		return nil, nil
+	}
+
+	var issues []dto.HealthIssue
+
+	checks := []func(string, *git.Repository, HealthOptions) ([]dto.HealthIssue, error){
+		checkHead,
+		checkRemotes,
+		checkBranches,
+		checkWorktree,
+		checkStashes,
+	}
+
+	for _, check := range checks {
+		found, err := check(path, repo, opts)
+		if err != nil {
+			return issues, err
+		}
+
+		issues = append(issues, found...)
+	}
+
+	return issues, nil
+}
+
+func checkHead(_ string, repo *git.Repository, _ HealthOptions) ([]dto.HealthIssue, error) {
+	head, err := repo.Head()
+	// a repository without commits has no HEAD to speak of
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if head.Name().IsBranch() {
+		return nil, nil
+	}
+
+	return []dto.HealthIssue{{
+		Kind:     dto.HealthDetachedHead,
+		Message:  "HEAD is detached",
+		Subjects: []string{head.Hash().String()},
+	}}, nil
+}
+
+func checkRemotes(_ string, repo *git.Repository, _ HealthOptions) ([]dto.HealthIssue, error) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(remotes) > 0 {
+		return nil, nil
+	}
+
+	return []dto.HealthIssue{{Kind: dto.HealthNoRemotes, Message: "repository has no remotes"}}, nil
+}
+
+// checkBranches reports local branches without an upstream and branches with commits their upstream doesn't have.
+func checkBranches(_ string, repo *git.Repository, _ HealthOptions) ([]dto.HealthIssue, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		noUpstream []string
+		unpushed   []string
+	)
+
+	err = branches.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+
+		branch, ok := cfg.Branches[name]
+		if !ok || branch.Remote == "" || branch.Merge == "" {
+			noUpstream = append(noUpstream, name)
+
+			return nil
+		}
+
+		upstream, err := repo.Reference(plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short()), true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			noUpstream = append(noUpstream, name)
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ahead, err := countAhead(repo, ref.Hash(), upstream.Hash())
+		if err != nil {
+			return err
+		}
+
+		if ahead > 0 {
+			unpushed = append(unpushed, fmt.Sprintf("%s (%d)", name, ahead))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []dto.HealthIssue
+
+	if len(noUpstream) > 0 {
+		issues = append(issues, dto.HealthIssue{
+			Kind:     dto.HealthNoUpstream,
+			Message:  fmt.Sprintf("%d branch(es) without an upstream", len(noUpstream)),
+			Subjects: noUpstream,
+		})
+	}
+
+	if len(unpushed) > 0 {
+		issues = append(issues, dto.HealthIssue{
+			Kind:     dto.HealthUnpushed,
+			Message:  fmt.Sprintf("%d branch(es) with unpushed commits", len(unpushed)),
+			Subjects: unpushed,
+		})
+	}
+
+	return issues, nil
+}
+
+// countAhead counts the commits reachable from local which are not reachable from upstream.
+func countAhead(repo *git.Repository, local, upstream plumbing.Hash) (int, error) {
+	if local == upstream {
+		return 0, nil
+	}
+
+	localCommit, err := repo.CommitObject(local)
+	if err != nil {
+		return 0, err
+	}
+
+	upstreamCommit, err := repo.CommitObject(upstream)
+	if err != nil {
+		return 0, err
+	}
+
+	bases, err := localCommit.MergeBase(upstreamCommit)
+	if err != nil {
+		return 0, err
+	}
+
+	stop := make(map[plumbing.Hash]bool, len(bases))
+	for _, base := range bases {
+		stop[base.Hash] = true
+	}
+
+	count := 0
+
+	iter := object.NewCommitPreorderIter(localCommit, stop, nil)
+
+	err = iter.ForEach(func(c *object.Commit) error {
+		count++
+
+		if count >= maxUnpushedWalk {
+			return storer.ErrStop
+		}
+
+		return nil
+	})
+
+	return count, err
+}
+
+// checkWorktree reports uncommitted changes older than the stale threshold and large untracked files.
+func checkWorktree(path string, repo *git.Repository, opts HealthOptions) ([]dto.HealthIssue, error) {
+	wt, err := repo.Worktree()
+	if errors.Is(err, git.ErrIsBareRepository) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stale  []string
+		large  []string
+		oldest time.Time
+	)
+
+	for file, st := range status {
+		info, err := os.Stat(filepath.Join(path, file))
+		// deleted files have nothing on disk to inspect
+		if err != nil {
+			continue
+		}
+
+		if st.Worktree == git.Untracked {
+			if opts.LargeFileSize > 0 && info.Size() > opts.LargeFileSize {
+				large = append(large, fmt.Sprintf("%s (%d MB)", file, info.Size()/(1<<20)))
+			}
+
+			continue
+		}
+
+		if opts.StaleAfter > 0 && opts.Now.Sub(info.ModTime()) > opts.StaleAfter {
+			stale = append(stale, file)
+
+			if oldest.IsZero() || info.ModTime().Before(oldest) {
+				oldest = info.ModTime()
+			}
+		}
+	}
+
+	sort.Strings(stale)
+	sort.Strings(large)
+
+	var issues []dto.HealthIssue
+
+	if len(stale) > 0 {
+		issues = append(issues, dto.HealthIssue{
+			Kind:     dto.HealthStaleChanges,
+			Message:  fmt.Sprintf("%d uncommitted file(s), oldest changed %d day(s) ago", len(stale), days(opts.Now.Sub(oldest))),
+			Subjects: stale,
+		})
+	}
+
+	if len(large) > 0 {
+		issues = append(issues, dto.HealthIssue{
+			Kind:     dto.HealthLargeUntracked,
+			Message:  fmt.Sprintf("%d large untracked file(s)", len(large)),
+			Subjects: large,
+		})
+	}
+
+	return issues, nil
+}
+
+// checkStashes reports stashes older than the stash threshold. go-git has no stash support so the stash reflog is
+// read directly. Worktrees share their stashes, the reflog is in the common git directory.
+func checkStashes(path string, _ *git.Repository, opts HealthOptions) ([]dto.HealthIssue, error) {
+	_, commonDir, err := GitDirs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(commonDir, "logs", "refs", "stash"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var stale []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		when, msg, ok := parseReflogLine(scanner.Text())
+		if !ok || opts.StashStaleAfter <= 0 || opts.Now.Sub(when) <= opts.StashStaleAfter {
+			continue
+		}
+
+		stale = append(stale, fmt.Sprintf("%s (%d days old)", msg, days(opts.Now.Sub(when))))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(stale) == 0 {
+		return nil, nil
+	}
+
+	return []dto.HealthIssue{{
+		Kind:     dto.HealthStaleStash,
+		Message:  fmt.Sprintf("%d stale stash(es)", len(stale)),
+		Subjects: stale,
+	}}, nil
+}
+
+// reflogTimeFields are the fields ending the head of a reflog entry: the unix timestamp and the timezone.
+const reflogTimeFields = 2
+
+// parseReflogLine parses a reflog entry of the form "<old> <new> <name> <<email>> <unix> <tz>\t<message>".
+func parseReflogLine(line string) (time.Time, string, bool) {
+	tab := strings.IndexByte(line, '\t')
+	if tab < 0 {
+		return time.Time{}, "", false
+	}
+
+	fields := strings.Fields(line[:tab])
+	if len(fields) < reflogTimeFields {
+		return time.Time{}, "", false
+	}
+
+	unix, err := strconv.ParseInt(fields[len(fields)-reflogTimeFields], 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return time.Unix(unix, 0), line[tab+1:], true
+}
+
+func days(d time.Duration) int {
+	return int(d / day)
+}
@@ -0,0 +1,115 @@
+package vcs
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/mattouille/proman/dto"
+)
+
+var (
+	ErrRelativeURL  = errors.New("relative submodule urls can't be looked up")
+	ErrNoRemoteHead = errors.New("the remote doesn't advertise the branch")
+)
+
+// Submodules lists the submodules of repo by path. With remote set the head of every submodule's remote is looked up
+// as well, which needs network access. A remote which can't be reached is reported on its submodule rather than
+// failing the list.
+func Submodules(repo *git.Repository, remote bool, creds Credentials) ([]dto.Submodule, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := wt.Submodules()
+	if err != nil {
+		return nil, err
+	}
+
+	submodules := make([]dto.Submodule, 0, len(subs))
+
+	for _, sub := range subs {
+		cfg := sub.Config()
+
+		submodule := dto.Submodule{Name: cfg.Name, Path: cfg.Path, URL: cfg.URL, Branch: cfg.Branch}
+
+		status, err := sub.Status()
+		if err != nil {
+			return nil, err
+		}
+
+		submodule.Pinned = hashString(status.Expected)
+		submodule.Current = hashString(status.Current)
+
+		if remote {
+			submodule.RemoteHead, err = remoteHead(cfg.URL, cfg.Branch, creds)
+			if err != nil {
+				submodule.RemoteError = err.Error()
+			}
+		}
+
+		submodules = append(submodules, submodule)
+	}
+
+	sort.Slice(submodules, func(i, j int) bool {
+		return submodules[i].Path < submodules[j].Path
+	})
+
+	return submodules, nil
+}
+
+// remoteHead returns the commit branch points at on the repository at url, or the remote's HEAD without a branch.
+func remoteHead(url, branch string, creds Credentials) (string, error) {
+	if strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../") {
+		return "", ErrRelativeURL
+	}
+
+	auth, err := Auth(url, creds)
+	if err != nil {
+		return "", err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: DefaultRemote, URLs: []string{url}})
+
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+
+	byName := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+
+	// "." follows the branch of the superproject, which can't be told from here
+	name := plumbing.HEAD
+	if branch != "" && branch != "." {
+		name = plumbing.NewBranchReferenceName(branch)
+	}
+
+	ref, ok := byName[name]
+	if ok && ref.Type() == plumbing.SymbolicReference {
+		ref, ok = byName[ref.Target()]
+	}
+
+	if !ok {
+		return "", ErrNoRemoteHead
+	}
+
+	return ref.Hash().String(), nil
+}
+
+// hashString returns the hash as a string, blank for the zero hash.
+func hashString(hash plumbing.Hash) string {
+	if hash.IsZero() {
+		return ""
+	}
+
+	return hash.String()
+}
@@ -0,0 +1,160 @@
+package vcs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/mattouille/proman/service/secrets"
+)
+
+// gitCommand runs the git command in dir, failing the test when it fails.
+func gitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	args = append([]string{"-c", "user.name=proman", "-c", "user.email=proman@example.com",
+		"-c", "protocol.file.allow=always"}, args...)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %s\n%s", args, err, out)
+	}
+}
+
+// headHash returns the commit HEAD of the repository at path points at.
+func headHash(t *testing.T, path string) string {
+	t.Helper()
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatalf("unable to open repository: %s", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("unable to read head: %s", err)
+	}
+
+	return head.Hash().String()
+}
+
+func TestSubmodules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is required to add submodules")
+	}
+
+	root := t.TempDir()
+	lib := filepath.Join(root, "lib")
+	app := filepath.Join(root, "app")
+
+	initRepo(t, lib)
+	initRepo(t, app)
+
+	gitCommand(t, app, "submodule", "add", lib, "vendor/lib")
+	gitCommand(t, app, "commit", "-m", "add lib")
+
+	pinned := headHash(t, lib)
+
+	gitCommand(t, lib, "commit", "--allow-empty", "-m", "update")
+
+	repo, err := git.PlainOpen(app)
+	if err != nil {
+		t.Fatalf("unable to open repository: %s", err)
+	}
+
+	submodules, err := Submodules(repo, false, nil)
+	if err != nil || len(submodules) != 1 {
+		t.Fatalf("listed %+v, %v, want the lib submodule", submodules, err)
+	}
+
+	sub := submodules[0]
+	if sub.Path != "vendor/lib" || sub.URL != lib || sub.Pinned != pinned || sub.Current != pinned {
+		t.Errorf("listed %+v, want vendor/lib pinned and checked out at %s", sub, pinned)
+	}
+
+	if sub.RemoteHead != "" {
+		t.Errorf("remote head %s, want none looked up without remote", sub.RemoteHead)
+	}
+
+	submodules, err = Submodules(repo, true, nil)
+	if err != nil || len(submodules) != 1 {
+		t.Fatalf("listed %+v, %v, want the lib submodule", submodules, err)
+	}
+
+	if head := headHash(t, lib); submodules[0].RemoteHead != head || submodules[0].RemoteError != "" {
+		t.Errorf("remote head %s (%s), want %s", submodules[0].RemoteHead, submodules[0].RemoteError, head)
+	}
+}
+
+func TestRemoteHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is required to create branches")
+	}
+
+	lib := filepath.Join(t.TempDir(), "lib")
+
+	initRepo(t, lib)
+
+	main := headHash(t, lib)
+
+	gitCommand(t, lib, "checkout", "-b", "next")
+	gitCommand(t, lib, "commit", "--allow-empty", "-m", "next")
+
+	next := headHash(t, lib)
+
+	tests := []struct {
+		name   string
+		url    string
+		branch string
+		want   string
+		err    error
+	}{
+		{name: "head", url: lib, want: next},
+		{name: "superproject branch", url: lib, branch: ".", want: next},
+		{name: "branch", url: lib, branch: "master", want: main},
+		{name: "unknown branch", url: lib, branch: "gone", err: ErrNoRemoteHead},
+		{name: "relative url", url: "../lib", err: ErrRelativeURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, err := remoteHead(tt.url, tt.branch, nil)
+			if !errors.Is(err, tt.err) || head != tt.want {
+				t.Errorf("remote head %s, %v, want %s, %v", head, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestRemoteHeadCredentials(t *testing.T) {
+	var username, password string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	// credentials are stored by hostname, without the port
+	creds := func(key string) (string, bool) {
+		return "proman:secret", key == secrets.GitCredentialKey("127.0.0.1")
+	}
+
+	_, err := remoteHead(server.URL+"/lib.git", "", creds)
+	if err == nil {
+		t.Fatalf("the remote was listed, want the forbidden response reported")
+	}
+
+	if username != "proman" || password != "secret" {
+		t.Errorf("authenticated as %q:%q, want the stored credentials", username, password)
+	}
+}
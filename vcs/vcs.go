@@ -0,0 +1,222 @@
+// Package vcs wraps version control access for projects. Only git is supported today.
+package vcs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Operation is a git operation which can be run against many repositories at once.
+type Operation string
+
+const (
+	OperationStatus   Operation = "status"
+	OperationFetch    Operation = "fetch"
+	OperationPull     Operation = "pull"
+	OperationCheckout Operation = "checkout"
+)
+
+// DefaultRemote is the remote used for fetching and pulling.
+const DefaultRemote = "origin"
+
+var (
+	ErrUnknownOperation = errors.New("unknown git operation")
+	ErrBranchRequired   = errors.New("branch is required to checkout")
+	ErrNotRepository    = errors.New("project is not a git repository")
+)
+
+// Options holds the parameters an operation may need.
+type Options struct {
+	// Branch is the branch to switch to for OperationCheckout
+	Branch string
+	// Credentials looks up stored credentials for remotes. Nil uses secrets.Lookup.
+	Credentials Credentials
+}
+
+// ParseOperation converts a frontend supplied operation name into an Operation.
+func ParseOperation(name string) (Operation, error) {
+	switch op := Operation(name); op {
+	case OperationStatus, OperationFetch, OperationPull, OperationCheckout:
+		return op, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownOperation, name)
+	}
+}
+
+// Open opens the git repository at path, which may be a linked worktree. It returns nil and no error if path is not a
+// git repository.
+func Open(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Run runs op against the repository at path and returns a short summary of the outcome.
+func Run(path string, op Operation, opts Options) (string, error) {
+	repo, err := Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	if repo == nil {
+		return "", ErrNotRepository
+	}
+
+	switch op {
+	case OperationStatus:
+		return status(repo)
+	case OperationFetch:
+		return fetch(repo, opts.Credentials)
+	case OperationPull:
+		return pull(repo, opts.Credentials)
+	case OperationCheckout:
+		return checkout(repo, opts.Branch)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownOperation, op)
+	}
+}
+
+func status(repo *git.Repository) (string, error) {
+	branch, err := describeHead(repo)
+	if err != nil {
+		return "", err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	st, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+
+	if st.IsClean() {
+		return fmt.Sprintf("%s: clean", branch), nil
+	}
+
+	return fmt.Sprintf("%s: %d changed file(s)", branch, len(st)), nil
+}
+
+func fetch(repo *git.Repository, creds Credentials) (string, error) {
+	auth, err := remoteAuth(repo, DefaultRemote, creds)
+	if err != nil {
+		return "", err
+	}
+
+	err = repo.Fetch(&git.FetchOptions{RemoteName: DefaultRemote, Auth: auth})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return "already up to date", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return "fetched", nil
+}
+
+func pull(repo *git.Repository, creds Credentials) (string, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	auth, err := remoteAuth(repo, DefaultRemote, creds)
+	if err != nil {
+		return "", err
+	}
+
+	err = wt.Pull(&git.PullOptions{RemoteName: DefaultRemote, Auth: auth})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return "already up to date", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return "pulled", nil
+}
+
+// checkout switches to branch, creating a local branch from the default remote when only the remote branch exists.
+func checkout(repo *git.Repository, branch string) (string, error) {
+	if branch == "" {
+		return "", ErrBranchRequired
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	opts := &git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch)}
+
+	_, err = repo.Reference(opts.Branch, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		remote, err := repo.Reference(plumbing.NewRemoteReferenceName(DefaultRemote, branch), true)
+		if err != nil {
+			return "", fmt.Errorf("branch %s does not exist: %w", branch, err)
+		}
+
+		opts.Hash = remote.Hash()
+		opts.Create = true
+	} else if err != nil {
+		return "", err
+	}
+
+	err = wt.Checkout(opts)
+	if err != nil {
+		return "", err
+	}
+
+	return "switched to " + branch, nil
+}
+
+// CurrentBranch returns the short name of the checked out branch. It returns a blank string when HEAD is detached or
+// the repository has no commits.
+func CurrentBranch(repo *git.Repository) string {
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+
+	return head.Name().Short()
+}
+
+// DefaultBranch returns the branch the remote's HEAD points at, as recorded by the last clone or
+// `git remote set-head`. It returns a blank string when it isn't known.
+func DefaultBranch(repo *git.Repository, remote string) string {
+	ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName(remote), false)
+	if err != nil || ref.Type() != plumbing.SymbolicReference {
+		return ""
+	}
+
+	return strings.TrimPrefix(ref.Target().String(), "refs/remotes/"+remote+"/")
+}
+
+// describeHead returns the short name of the checked out branch, or the abbreviated commit when HEAD is detached.
+func describeHead(repo *git.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !head.Name().IsBranch() {
+		return "detached at " + head.Hash().String()[:7], nil
+	}
+
+	return head.Name().Short(), nil
+}
@@ -0,0 +1,319 @@
+package vcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/mattouille/proman/dto"
+)
+
+var (
+	// ErrNoGitCommand is returned when managing worktrees without the git command, go-git can only read them
+	ErrNoGitCommand   = errors.New("the git command is required to manage worktrees")
+	ErrNotWorktree    = errors.New("directory is not a worktree of the repository")
+	ErrMainWorktree   = errors.New("the main worktree can't be removed")
+	ErrWorktreeExists = errors.New("a file or directory already exists at the worktree path")
+	ErrWorktreeDirty  = errors.New("the worktree has changes")
+	ErrInvalidBranch  = errors.New("branch names can't start with a dash")
+)
+
+// gitDirPrefix starts the .git file of a linked worktree.
+const gitDirPrefix = "gitdir:"
+
+// shortHashLength is the length commits are abbreviated to, as git does by default.
+const shortHashLength = 7
+
+// GitDirs returns the git directory of the working tree at path and the common directory its worktrees share. They are
+// both path/.git for the main worktree. A linked worktree has a .git file pointing at its own git directory inside the
+// common one.
+func GitDirs(path string) (gitDir, commonDir string, err error) {
+	dotGit := filepath.Join(path, git.GitDirName)
+
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", "", err
+	}
+
+	if info.IsDir() {
+		return dotGit, dotGit, nil
+	}
+
+	gitDir, err = readPointer(dotGit, gitDirPrefix)
+	if err != nil {
+		return "", "", err
+	}
+
+	gitDir = resolve(path, gitDir)
+
+	commonDir, err = readPointer(filepath.Join(gitDir, "commondir"), "")
+	if os.IsNotExist(err) {
+		return gitDir, gitDir, nil
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return gitDir, resolve(gitDir, commonDir), nil
+}
+
+// MainWorktree returns the path of the main worktree of the linked worktree at path. It returns a blank path when
+// path is the main worktree or no repository at all.
+func MainWorktree(path string) (string, error) {
+	gitDir, commonDir, err := GitDirs(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	if err != nil || gitDir == commonDir {
+		return "", err
+	}
+
+	return mainPath(commonDir), nil
+}
+
+// Worktrees lists the working trees of the repository at path, which can be any of them, the main worktree first and
+// the linked ones by path.
+func Worktrees(path string) ([]dto.Worktree, error) {
+	_, commonDir, err := GitDirs(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotRepository
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	main := dto.Worktree{Path: mainPath(commonDir), Main: true}
+	main.Branch, main.Head = readHead(commonDir)
+
+	worktrees := []dto.Worktree{main}
+
+	entries, err := ioutil.ReadDir(filepath.Join(commonDir, "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	linked := make([]dto.Worktree, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		gitDir := filepath.Join(commonDir, "worktrees", entry.Name())
+
+		// gitdir holds the path of the .git file in the working tree
+		dotGit, err := readPointer(filepath.Join(gitDir, "gitdir"), "")
+		if err != nil {
+			continue
+		}
+
+		worktree := dto.Worktree{Path: filepath.Dir(resolve(gitDir, dotGit))}
+		worktree.Branch, worktree.Head = readHead(gitDir)
+
+		_, err = os.Stat(filepath.Join(gitDir, "locked"))
+		worktree.Locked = err == nil
+
+		_, err = os.Stat(worktree.Path)
+		worktree.Prunable = os.IsNotExist(err)
+
+		linked = append(linked, worktree)
+	}
+
+	sort.Slice(linked, func(i, j int) bool {
+		return linked[i].Path < linked[j].Path
+	})
+
+	return append(worktrees, linked...), nil
+}
+
+// Branches returns the local branches of repo by name.
+func Branches(repo *git.Repository) ([]string, error) {
+	refs, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, ref.Name().Short())
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(branches)
+
+	return branches, nil
+}
+
+// AddWorktree checks branch out in a new linked worktree at dir of the repository at path. With create a new branch
+// is started from the current HEAD of path instead.
+func AddWorktree(path, dir, branch string, create bool) error {
+	if branch == "" {
+		return ErrBranchRequired
+	}
+
+	// the value of -b can't be protected by --, and git refuses such names anyway
+	if strings.HasPrefix(branch, "-") {
+		return fmt.Errorf("%w: %s", ErrInvalidBranch, branch)
+	}
+
+	_, err := os.Stat(dir)
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrWorktreeExists, dir)
+	}
+
+	args := []string{"worktree", "add", "--", dir, branch}
+	if create {
+		args = []string{"worktree", "add", "-b", branch, "--", dir}
+	}
+
+	return runGit(path, args...)
+}
+
+// RemoveWorktree removes the linked worktree at dir of the repository at path. Unless force is set a worktree with
+// changes or untracked files is kept and ErrWorktreeDirty returned.
+func RemoveWorktree(path, dir string, force bool) error {
+	worktrees, err := Worktrees(path)
+	if err != nil {
+		return err
+	}
+
+	for _, worktree := range worktrees {
+		if worktree.Path != filepath.Clean(dir) {
+			continue
+		}
+
+		if worktree.Main {
+			return ErrMainWorktree
+		}
+
+		// a worktree whose directory is gone is only forgotten
+		if worktree.Prunable {
+			return runGit(path, "worktree", "prune")
+		}
+
+		if force {
+			return runGit(path, "worktree", "remove", "--force", "--", worktree.Path)
+		}
+
+		clean, err := isClean(worktree.Path)
+		if err != nil {
+			return err
+		}
+
+		if !clean {
+			return fmt.Errorf("%w: %s", ErrWorktreeDirty, worktree.Path)
+		}
+
+		return runGit(path, "worktree", "remove", "--", worktree.Path)
+	}
+
+	return fmt.Errorf("%w: %s", ErrNotWorktree, dir)
+}
+
+// isClean reports whether the worktree at path has neither changes nor untracked files.
+func isClean(path string) (bool, error) {
+	repo, err := Open(path)
+	if err != nil || repo == nil {
+		return false, err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+
+	return status.IsClean(), nil
+}
+
+// runGit runs the git command in dir. Its error output becomes the error.
+func runGit(dir string, args ...string) error {
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		return ErrNoGitCommand
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git %s: %s", args[0], msg)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// readHead returns the branch checked out according to the HEAD file in gitDir, or the abbreviated commit when HEAD is
+// detached.
+func readHead(gitDir string) (branch, head string) {
+	ref, err := readPointer(filepath.Join(gitDir, "HEAD"), "ref:")
+	if err == nil {
+		return strings.TrimPrefix(ref, "refs/heads/"), ""
+	}
+
+	hash, err := readPointer(filepath.Join(gitDir, "HEAD"), "")
+	if err != nil || len(hash) < shortHashLength {
+		return "", ""
+	}
+
+	return "", hash[:shortHashLength]
+}
+
+// readPointer reads the single line file at path and returns it without prefix. It fails when the line doesn't start
+// with prefix.
+func readPointer(path, prefix string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, prefix) {
+		return "", fmt.Errorf("unexpected content in %s", path)
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(line, prefix)), nil
+}
+
+// resolve makes target, read from a file in dir, absolute.
+func resolve(dir, target string) string {
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target)
+	}
+
+	return filepath.Join(dir, target)
+}
+
+// mainPath returns the main worktree of the common git directory, the directory containing it.
+func mainPath(commonDir string) string {
+	return filepath.Dir(commonDir)
+}
@@ -0,0 +1,93 @@
+package vcs
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initRepo creates a repository at path with a single commit.
+func initRepo(t *testing.T, path string) {
+	t.Helper()
+
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		t.Fatalf("unable to init repository: %s", err)
+	}
+
+	err = os.WriteFile(filepath.Join(path, "readme.md"), []byte("# app\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err == nil {
+		_, err = wt.Add("readme.md")
+	}
+
+	if err == nil {
+		_, err = wt.Commit("initial", &git.CommitOptions{
+			Author: &object.Signature{Name: "proman", Email: "proman@example.com", When: time.Now()},
+		})
+	}
+
+	if err != nil {
+		t.Fatalf("unable to commit: %s", err)
+	}
+}
+
+func TestAddWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is required to manage worktrees")
+	}
+
+	root := t.TempDir()
+	path := filepath.Join(root, "app")
+
+	initRepo(t, path)
+
+	tests := []struct {
+		name   string
+		dir    string
+		branch string
+		create bool
+		err    error
+	}{
+		{name: "new branch", dir: "app-feature", branch: "feature", create: true},
+		{name: "dir starting with a dash", dir: "-app", branch: "dash", create: true},
+		{name: "branch starting with a dash", dir: "app-flag", branch: "--orphan", create: true, err: ErrInvalidBranch},
+		{name: "existing branch starting with a dash", dir: "app-flag", branch: "-f", err: ErrInvalidBranch},
+		{name: "no branch", dir: "app-none", err: ErrBranchRequired},
+		{name: "existing dir", dir: "app", branch: "other", create: true, err: ErrWorktreeExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(root, tt.dir)
+
+			err := AddWorktree(path, dir, tt.branch, tt.create)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+
+			if tt.err != nil {
+				return
+			}
+
+			main, err := MainWorktree(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if main != path {
+				t.Errorf("main worktree %s, want %s", main, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// compat.js provides the wails v1 globals the frontend calls on top of the wails v2 runtime, until the frontend uses
+// the v2 runtime itself.
+(function () {
+	const ready = (callback) => {
+		if (document.readyState === "loading") {
+			document.addEventListener("DOMContentLoaded", callback);
+		} else {
+			callback();
+		}
+	};
+
+	window.wails = {
+		_: {
+			Init: ready,
+		},
+		Events: {
+			On: (name, callback) => window.runtime.EventsOn(name, callback),
+			OnMultiple: (name, callback, max) => window.runtime.EventsOnMultiple(name, callback, max === undefined ? -1 : max),
+			Emit: (name, ...data) => window.runtime.EventsEmit(name, ...data),
+		},
+		Browser: {
+			OpenURL: (url) => window.runtime.BrowserOpenURL(url),
+		},
+	};
+
+	// features of this shell the wails v1 one lacks, the frontend only offers their settings when present
+	window.shell = {
+		startMinimized: true,
+	};
+
+	// the core services are bound as window.go.core.<Service>
+	window.backend = new Proxy({}, {
+		get: (_, service) => window.go.core[service],
+	});
+})();
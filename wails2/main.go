@@ -0,0 +1,172 @@
+// Command wails2 is proman on wails v2. It serves the same core services and frontend as the wails v1 app in the
+// repository root, which remains the supported build until this one reaches parity. Build it with wails build from this
+// directory.
+package main
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/mattouille/proman/core"
+	"github.com/mattouille/proman/events"
+	"github.com/mattouille/proman/frontend"
+	"github.com/mattouille/proman/platform"
+	"github.com/mattouille/proman/service/config"
+	"github.com/wailsapp/wails/v2"
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	DefaultWidth  = 360
+	DefaultHeight = 640
+)
+
+//go:embed index.html compat.js
+var shell embed.FS
+
+func main() {
+	opts, _ := core.ParseArgs(os.Args)
+
+	services, err := core.Load(opts)
+	if errors.Is(err, core.ErrForwarded) {
+		os.Exit(0)
+	}
+
+	if err != nil {
+		log.Printf("Unable to start proman: %s", err)
+
+		os.Exit(1)
+	}
+
+	public, err := fs.Sub(frontend.Public, "public")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// wails v2 has no tray, so the window is minimized rather than hidden to stay reachable from the taskbar or dock
+	cfg, err := config.Unmarshal()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	startState := options.Normal
+	if cfg.StartMinimized && !opts.Launcher {
+		startState = options.Minimised
+	}
+
+	a := &app{services: services}
+
+	err = wails.Run(&options.App{
+		Title:            "proman",
+		Width:            DefaultWidth,
+		MinWidth:         DefaultWidth,
+		Height:           DefaultHeight,
+		MinHeight:        DefaultHeight,
+		BackgroundColour: &options.RGBA{R: 0x13, G: 0x13, B: 0x13, A: 0xff},
+		AssetServer:      &assetserver.Options{Assets: overlay{shell, public}},
+		Menu:             a.menu(),
+		WindowStartState: startState,
+		OnStartup:        a.startup,
+		OnShutdown:       a.shutdown,
+		Bind: []interface{}{
+			services.Config,
+			services.Validate,
+			services.Projects,
+			services.Setup,
+			services.Groups,
+			services.EditorConfig,
+			services.Profiles,
+			services.Secrets,
+			services.Actions,
+			services.Instance,
+		},
+	})
+	if err != nil {
+		log.Printf("Exited with errors: %s", err)
+
+		os.Exit(1)
+	}
+}
+
+// app ties the lifecycle of the core services to the wails v2 context.
+type app struct {
+	ctx      context.Context
+	services *core.App
+	bus      *events.Bus
+}
+
+func (a *app) startup(ctx context.Context) {
+	a.ctx = ctx
+
+	p := newPlatform(ctx)
+	log := p.Log("shell")
+	a.bus = events.NewBus(p.Events, log)
+
+	err := a.services.Start(p)
+	if err != nil {
+		log.ErrorFields("Unable to start services", platform.Fields{"error": err})
+	}
+
+	// unlike wails v1, v2 can raise its window
+	show := func(...interface{}) {
+		runtime.WindowUnminimise(ctx)
+		runtime.WindowShow(ctx)
+	}
+
+	a.bus.On(events.InstanceFocus, show)
+	a.bus.On(events.LauncherShow, show)
+}
+
+func (a *app) shutdown(context.Context) {
+	a.services.Stop()
+}
+
+// menu builds the native menu. The frontend listens for the events its items emit.
+func (a *app) menu() *menu.Menu {
+	m := menu.NewMenu()
+
+	// macOS expects the application and edit menus, which also provide the clipboard shortcuts
+	m.Append(menu.AppMenu())
+	m.Append(menu.EditMenu())
+
+	projects := m.AddSubmenu("Projects")
+	projects.AddText("Refresh", keys.CmdOrCtrl("r"), func(*menu.CallbackData) {
+		_, err := a.services.Projects.GetAll(true, nil)
+		if err != nil {
+			runtime.LogErrorf(a.ctx, "Unable to refresh projects: %s", err)
+
+			return
+		}
+
+		a.bus.Emit(events.ProjectsChanged)
+	})
+	projects.AddSeparator()
+	projects.AddText("Quit", keys.CmdOrCtrl("q"), func(*menu.CallbackData) {
+		runtime.Quit(a.ctx)
+	})
+
+	return m
+}
+
+// overlay serves the files of the shell in place of those of the built frontend.
+type overlay struct {
+	shell    fs.FS
+	frontend fs.FS
+}
+
+func (o overlay) Open(name string) (fs.File, error) {
+	f, err := o.shell.Open(name)
+	if err == nil {
+		return f, nil
+	}
+
+	return o.frontend.Open(name)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattouille/proman/platform"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// newPlatform adapts the wails v2 runtime, which is reached through the context passed to OnStartup, for the core
+// services.
+func newPlatform(ctx context.Context) *platform.Runtime {
+	return &platform.Runtime{
+		Events:  eventsV2{ctx},
+		Dialog:  dialogV2{ctx},
+		Browser: browserV2{ctx},
+		Log: func(name string) platform.Logger {
+			return loggerV2{ctx: ctx, prefix: name}
+		},
+	}
+}
+
+type eventsV2 struct {
+	ctx context.Context
+}
+
+func (e eventsV2) On(eventName string, callback func(optionalData ...interface{})) {
+	runtime.EventsOn(e.ctx, eventName, callback)
+}
+
+func (e eventsV2) Emit(eventName string, optionalData ...interface{}) {
+	runtime.EventsEmit(e.ctx, eventName, optionalData...)
+}
+
+type dialogV2 struct {
+	ctx context.Context
+}
+
+func (d dialogV2) SelectDirectory() string {
+	// a failing dialog is treated like a cancelled one
+	dir, err := runtime.OpenDirectoryDialog(d.ctx, runtime.OpenDialogOptions{CanCreateDirectories: true})
+	if err != nil {
+		runtime.LogErrorf(d.ctx, "Unable to open directory dialog: %s", err)
+
+		return ""
+	}
+
+	return dir
+}
+
+type browserV2 struct {
+	ctx context.Context
+}
+
+func (b browserV2) OpenURL(url string) error {
+	runtime.BrowserOpenURL(b.ctx, url)
+
+	return nil
+}
+
+// loggerV2 prefixes messages with the name of the service, as the wails v1 logger does, and appends the fields.
+type loggerV2 struct {
+	ctx    context.Context
+	prefix string
+}
+
+func (l loggerV2) Info(message string) {
+	runtime.LogInfo(l.ctx, l.format(message, nil))
+}
+
+func (l loggerV2) Debug(message string) {
+	runtime.LogDebug(l.ctx, l.format(message, nil))
+}
+
+func (l loggerV2) Debugf(message string, args ...interface{}) {
+	runtime.LogDebug(l.ctx, l.format(fmt.Sprintf(message, args...), nil))
+}
+
+func (l loggerV2) DebugFields(message string, fields platform.Fields) {
+	runtime.LogDebug(l.ctx, l.format(message, fields))
+}
+
+func (l loggerV2) Errorf(message string, args ...interface{}) {
+	runtime.LogError(l.ctx, l.format(fmt.Sprintf(message, args...), nil))
+}
+
+func (l loggerV2) ErrorFields(message string, fields platform.Fields) {
+	runtime.LogError(l.ctx, l.format(message, fields))
+}
+
+func (l loggerV2) format(message string, fields platform.Fields) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[%s] %s", l.prefix, message)
+
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, fields[key])
+	}
+
+	return b.String()
+}